@@ -0,0 +1,316 @@
+package dgofw
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Flag is a command line style option of a message handler,
+	// e.g. ``--silent`` or ``-n 5``.
+	Flag struct {
+		// Name is the long name, used as ``--name``.
+		Name string
+
+		// Short is the optional single letter name, used as ``-s``.
+		Short string
+
+		// Usage describes the flag in generated help.
+		Usage string
+
+		// Value is true if the flag takes a value, e.g. ``-n 5`` or ``--count=5``.
+		Value bool
+
+		// Default is the value of a value flag that was not given.
+		Default string
+	}
+
+	argToken struct {
+		value  string
+		quoted bool
+	}
+)
+
+// SplitArgs splits content into arguments.
+//
+// Arguments are separated by whitespace. Double or single quotes at the start
+// of an argument group several words into one, a backslash escapes a quote, a space
+// or another backslash, and inline code or code blocks are kept intact as a single argument.
+func SplitArgs(content string) []string {
+	toks := tokenizeArgs(content)
+	result := make([]string, len(toks))
+	for i, tok := range toks {
+		result[i] = tok.value
+	}
+	return result
+}
+
+func tokenizeArgs(content string) []argToken {
+	var (
+		result  []argToken
+		cur     strings.Builder
+		quoted  bool
+		pending bool
+	)
+
+	flush := func() {
+		if pending {
+			result = append(result, argToken{cur.String(), quoted})
+		}
+		cur.Reset()
+		quoted, pending = false, false
+	}
+
+	rs := []rune(content)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case isEscape(rs, i):
+			i++
+			cur.WriteRune(rs[i])
+			pending = true
+		case (r == '"' || r == '\'') && !pending:
+			end := indexRune(rs, i+1, r)
+			if end < 0 {
+				cur.WriteRune(r)
+				pending = true
+				continue
+			}
+			cur.WriteString(unescape(rs[i+1 : end]))
+			i = end
+			quoted, pending = true, true
+		case r == '`':
+			n := 1
+			if i+2 < len(rs) && rs[i+1] == '`' && rs[i+2] == '`' {
+				n = 3
+			}
+			end := indexFence(rs, i+n, n)
+			if end < 0 {
+				cur.WriteRune(r)
+				pending = true
+				continue
+			}
+			cur.WriteString(string(rs[i : end+n]))
+			i = end + n - 1
+			pending = true
+		case r == ' ' || r == '\n' || r == '\t' || r == '\r':
+			flush()
+		default:
+			cur.WriteRune(r)
+			pending = true
+		}
+	}
+	flush()
+	return result
+}
+
+func indexRune(rs []rune, from int, r rune) int {
+	for i := from; i < len(rs); i++ {
+		if isEscape(rs, i) {
+			i++
+			continue
+		}
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexFence(rs []rune, from, n int) int {
+	for i := from; i+n <= len(rs); i++ {
+		match := true
+		for j := 0; j < n; j++ {
+			if rs[i+j] != '`' {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(rs []rune) string {
+	var b strings.Builder
+	for i := 0; i < len(rs); i++ {
+		if isEscape(rs, i) {
+			i++
+		}
+		b.WriteRune(rs[i])
+	}
+	return b.String()
+}
+
+// isEscape reports whether ``rs[i]`` is a backslash escaping the next rune.
+// Other backslashes, e.g. in paths, are kept as they are.
+func isEscape(rs []rune, i int) bool {
+	if rs[i] != '\\' || i+1 >= len(rs) {
+		return false
+	}
+	switch rs[i+1] {
+	case '"', '\'', ' ', '\\':
+		return true
+	}
+	return false
+}
+
+func (h *MsgHandler) lookupFlag(arg string) (*Flag, string, bool) {
+	var name, value string
+	var hasValue bool
+	switch {
+	case strings.HasPrefix(arg, "--") && len(arg) > 2:
+		name = arg[2:]
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value, hasValue = name[:i], name[i+1:], true
+		}
+		for _, f := range h.flags {
+			if f.Name == name {
+				return f, value, hasValue
+			}
+		}
+	case strings.HasPrefix(arg, "-") && len(arg) == 2:
+		name = arg[1:]
+		for _, f := range h.flags {
+			if f.Short == name {
+				return f, "", false
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// parseFlags pulls the handler's flags out of ``toks`` and returns
+// the remaining positional arguments and the flag values.
+func (h *MsgHandler) parseFlags(toks []argToken) ([]string, map[string]string) {
+	flags := make(map[string]string)
+	for _, f := range h.flags {
+		if f.Value && f.Default != "" {
+			flags[f.Name] = f.Default
+		}
+	}
+
+	args := make([]string, 0, len(toks))
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.quoted || len(h.flags) == 0 {
+			args = append(args, tok.value)
+			continue
+		}
+
+		if tok.value == "--" {
+			for _, rest := range toks[i+1:] {
+				args = append(args, rest.value)
+			}
+			break
+		}
+
+		f, value, hasValue := h.lookupFlag(tok.value)
+		if f == nil {
+			args = append(args, tok.value)
+			continue
+		}
+
+		switch {
+		case !f.Value:
+			flags[f.Name] = "true"
+		case hasValue:
+			flags[f.Name] = value
+		case i+1 < len(toks):
+			i++
+			flags[f.Name] = toks[i].value
+		default:
+			flags[f.Name] = ""
+		}
+	}
+	return args, flags
+}
+
+// Flags declares flags on the handler.
+func (h *MsgHandler) Flags(flags ...*Flag) *MsgHandler {
+	h.flags = append(h.flags, flags...)
+	return h
+}
+
+// Describe sets the description shown in generated help.
+func (h *MsgHandler) Describe(desc string) *MsgHandler {
+	h.desc = desc
+	return h
+}
+
+// Usage generates a usage string for the handler from its pattern and flags.
+func (h *MsgHandler) Usage() string {
+	keys := strings.Fields(h.pattern)
+	for i, key := range keys {
+		if strings.HasPrefix(key, "{") && strings.HasSuffix(key, "}") {
+			keys[i] = "<" + strings.Trim(key, "{}") + ">"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(keys, " "))
+	for _, f := range h.flags {
+		b.WriteString(" [")
+		if f.Short != "" {
+			b.WriteString("-" + f.Short + "|")
+		}
+		b.WriteString("--" + f.Name)
+		if f.Value {
+			b.WriteString(" <value>")
+		}
+		b.WriteString("]")
+	}
+
+	if h.desc != "" {
+		b.WriteString("\n    " + h.desc)
+	}
+
+	for _, f := range h.flags {
+		name := "--" + f.Name
+		if f.Short != "" {
+			name = "-" + f.Short + ", " + name
+		}
+		b.WriteString(fmt.Sprintf("\n    %s\t%s", name, f.Usage))
+		if f.Default != "" {
+			b.WriteString(fmt.Sprintf(" (default %s)", f.Default))
+		}
+	}
+	return b.String()
+}
+
+// Help generates usage strings for all registered message handlers.
+func (c *DiscordClient) Help() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	usages := make([]string, len(c.handlers))
+	for i, h := range c.handlers {
+		usages[i] = h.Usage()
+	}
+	return strings.Join(usages, "\n")
+}
+
+// Flag returns the value of a flag, or its default if it wasn't given.
+// Boolean flags are ``"true"`` when present.
+func (m *DiscordMessage) Flag(name string) string {
+	m.RLock()
+	defer m.RUnlock()
+	return m.flags[name]
+}
+
+// HasFlag reports whether a flag was given or has a default.
+func (m *DiscordMessage) HasFlag(name string) bool {
+	m.RLock()
+	defer m.RUnlock()
+	_, ok := m.flags[name]
+	return ok
+}
+
+// Args returns the positional arguments of the message, without flags.
+func (m *DiscordMessage) Args() []string {
+	m.RLock()
+	defer m.RUnlock()
+	return m.args
+}
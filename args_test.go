@@ -0,0 +1,116 @@
+package dgofw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"a b  c", []string{"a", "b", "c"}},
+		{"a\tb\nc", []string{"a", "b", "c"}},
+		{`"a b" c`, []string{"a b", "c"}},
+		{`'a b' c`, []string{"a b", "c"}},
+		{`"a \"b\"" c`, []string{`a "b"`, "c"}},
+		{`a\ b c`, []string{"a b", "c"}},
+		{`\"a b`, []string{`"a`, "b"}},
+		{`a\\ b`, []string{`a\`, "b"}},
+		{`C:\Users\me`, []string{`C:\Users\me`}},
+		{`"C:\Program Files\x"`, []string{`C:\Program Files\x`}},
+		{`\n \d+`, []string{`\n`, `\d+`}},
+		{`"unclosed quote`, []string{`"unclosed`, "quote"}},
+		{`it's fine`, []string{"it's", "fine"}},
+		{"`a b` c", []string{"`a b`", "c"}},
+		{"```go\nx := 1\n``` c", []string{"```go\nx := 1\n```", "c"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitArgs(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func testFlagHandler() *MsgHandler {
+	return (&MsgHandler{pattern: "!ban {user} {reason}"}).Describe("Bans a user.").Flags(
+		&Flag{Name: "silent", Short: "s", Usage: "don't announce it"},
+		&Flag{Name: "days", Short: "d", Usage: "days of messages to delete", Value: true, Default: "1"},
+		&Flag{Name: "note", Usage: "note for the log", Value: true},
+	)
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		in    string
+		args  []string
+		flags map[string]string
+	}{
+		{"!ban 1 spam", []string{"!ban", "1", "spam"}, map[string]string{"days": "1"}},
+		{"!ban -s 1", []string{"!ban", "1"}, map[string]string{"silent": "true", "days": "1"}},
+		{"!ban --silent 1", []string{"!ban", "1"}, map[string]string{"silent": "true", "days": "1"}},
+		{"!ban -d 7 1", []string{"!ban", "1"}, map[string]string{"days": "7"}},
+		{"!ban --days 7 1", []string{"!ban", "1"}, map[string]string{"days": "7"}},
+		{"!ban --days=7 1", []string{"!ban", "1"}, map[string]string{"days": "7"}},
+		{`!ban --note=a\ b 1`, []string{"!ban", "1"}, map[string]string{"days": "1", "note": "a b"}},
+		{`!ban --note "a b" 1`, []string{"!ban", "1"}, map[string]string{"days": "1", "note": "a b"}},
+		{"!ban 1 --note", []string{"!ban", "1"}, map[string]string{"days": "1", "note": ""}},
+		{"!ban 1 -- -s --days 2", []string{"!ban", "1", "-s", "--days", "2"}, map[string]string{"days": "1"}},
+		{`!ban 1 "-s"`, []string{"!ban", "1", "-s"}, map[string]string{"days": "1"}},
+		{"!ban -x --unknown 1", []string{"!ban", "-x", "--unknown", "1"}, map[string]string{"days": "1"}},
+	}
+
+	h := testFlagHandler()
+	for _, tt := range tests {
+		args, flags := h.parseFlags(tokenizeArgs(tt.in))
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%q: got args %q, want %q", tt.in, args, tt.args)
+		}
+		if !reflect.DeepEqual(flags, tt.flags) {
+			t.Errorf("%q: got flags %v, want %v", tt.in, flags, tt.flags)
+		}
+	}
+}
+
+func TestParseFlagsWithoutFlags(t *testing.T) {
+	h := &MsgHandler{pattern: "!echo {text}"}
+	args, flags := h.parseFlags(tokenizeArgs("!echo -s --x=y"))
+	if want := []string{"!echo", "-s", "--x=y"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %q, want %q", args, want)
+	}
+	if len(flags) != 0 {
+		t.Errorf("got flags %v, want none", flags)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	want := strings.Join([]string{
+		"!ban <user> <reason> [-s|--silent] [-d|--days <value>] [--note <value>]",
+		"    Bans a user.",
+		"    -s, --silent\tdon't announce it",
+		"    -d, --days\tdays of messages to delete (default 1)",
+		"    --note\tnote for the log",
+	}, "\n")
+	if got := testFlagHandler().Usage(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := (&MsgHandler{pattern: "!ping"}).Usage(); got != "!ping" {
+		t.Errorf("got %q, want %q", got, "!ping")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	c, _ := newTestClient()
+	c.OnMessage("!ping", false, func(*DiscordMessage) {})
+	c.OnMessage("!say {text}", false, func(*DiscordMessage) {}).Describe("Repeats text.")
+
+	want := "!ping\n!say <text>\n    Repeats text."
+	if got := c.Help(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
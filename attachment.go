@@ -0,0 +1,241 @@
+package dgofw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrAttachmentTooLarge is returned when an attachment exceeds the download size cap.
+var ErrAttachmentTooLarge = errors.New("attachment exceeds the maximum download size")
+
+// DefaultMaxAttachmentSize is the default download size cap, 8 MiB.
+const DefaultMaxAttachmentSize = 8 << 20
+
+// Attachment types usable in message patterns, e.g. ``{file:image}``.
+const (
+	AttachmentAny   = "attachment"
+	AttachmentImage = "image"
+	AttachmentText  = "text"
+)
+
+type DiscordAttachment struct {
+	a      *discordgo.MessageAttachment
+	client *DiscordClient
+}
+
+func NewDiscordAttachment(client *DiscordClient, a *discordgo.MessageAttachment) *DiscordAttachment {
+	return &DiscordAttachment{
+		a:      a,
+		client: client,
+	}
+}
+
+func (a *DiscordAttachment) ID() string {
+	return a.a.ID
+}
+
+func (a *DiscordAttachment) Filename() string {
+	return a.a.Filename
+}
+
+func (a *DiscordAttachment) URL() string {
+	return a.a.URL
+}
+
+func (a *DiscordAttachment) ProxyURL() string {
+	return a.a.ProxyURL
+}
+
+// Size is the size of the file in bytes.
+func (a *DiscordAttachment) Size() int {
+	return a.a.Size
+}
+
+// Width is the width of an image, or 0.
+func (a *DiscordAttachment) Width() int {
+	return a.a.Width
+}
+
+// Height is the height of an image, or 0.
+func (a *DiscordAttachment) Height() int {
+	return a.a.Height
+}
+
+// ContentType guesses the MIME type of the attachment from its file extension.
+func (a *DiscordAttachment) ContentType() string {
+	t := mime.TypeByExtension(strings.ToLower(path.Ext(a.Filename())))
+	if t == "" {
+		return "application/octet-stream"
+	}
+	return t
+}
+
+// IsImage reports whether the attachment is an image.
+func (a *DiscordAttachment) IsImage() bool {
+	return a.Width() > 0 || strings.HasPrefix(a.ContentType(), "image/")
+}
+
+// IsText reports whether the attachment is a text file.
+func (a *DiscordAttachment) IsText() bool {
+	return strings.HasPrefix(a.ContentType(), "text/")
+}
+
+// Is reports whether the attachment matches a pattern attachment type.
+func (a *DiscordAttachment) Is(kind string) bool {
+	switch kind {
+	case AttachmentImage:
+		return a.IsImage()
+	case AttachmentText:
+		return a.IsText()
+	default:
+		return true
+	}
+}
+
+// Open streams the attachment.
+//
+// Reading fails with ``ErrAttachmentTooLarge`` past the client's ``MaxAttachmentSize``.
+func (a *DiscordAttachment) Open() (io.ReadCloser, error) {
+	return a.OpenContext(context.Background())
+}
+
+// OpenContext is like ``Open`` but the request is bound to ``ctx``.
+func (a *DiscordAttachment) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	max := a.client.maxAttachmentSize()
+	if a.Size() > max {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	req, err := http.NewRequest("GET", a.URL(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.client.ses.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", a.Filename(), resp.Status)
+	}
+
+	return &limitedBody{
+		r: io.LimitReader(resp.Body, int64(max)+1),
+		c: resp.Body,
+		n: int64(max),
+	}, nil
+}
+
+// Download reads the whole attachment into memory.
+func (a *DiscordAttachment) Download(ctx context.Context) ([]byte, error) {
+	body, err := a.OpenContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+type limitedBody struct {
+	r    io.Reader
+	c    io.Closer
+	n    int64
+	read int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.n {
+		return n - int(l.read-l.n), ErrAttachmentTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.c.Close()
+}
+
+func (c *DiscordClient) maxAttachmentSize() int {
+	c.RLock()
+	defer c.RUnlock()
+	if c.MaxAttachmentSize <= 0 {
+		return DefaultMaxAttachmentSize
+	}
+	return c.MaxAttachmentSize
+}
+
+// Attachments returns the files attached to the message.
+func (m *DiscordMessage) Attachments() []*DiscordAttachment {
+	result := make([]*DiscordAttachment, len(m.m.Attachments))
+	for i, a := range m.m.Attachments {
+		result[i] = NewDiscordAttachment(m.client, a)
+	}
+	return result
+}
+
+// Attachment returns the attachment matched by a ``{name:type}`` pattern key.
+func (m *DiscordMessage) Attachment(key string) *DiscordAttachment {
+	m.RLock()
+	defer m.RUnlock()
+	return m.files[key]
+}
+
+// attachmentKey splits a ``{name:type}`` pattern key.
+func attachmentKey(key string) (name, kind string, ok bool) {
+	if !strings.HasPrefix(key, "{") || !strings.HasSuffix(key, "}") {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.Trim(key, "{}"), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	switch parts[1] {
+	case AttachmentAny, AttachmentImage, AttachmentText:
+		return parts[0], parts[1], true
+	}
+	return "", "", false
+}
+
+// matchAttachments splits attachment keys off the pattern keys, and assigns
+// an attachment to each. It fails if a required attachment is missing.
+func matchAttachments(keys []string, attachments []*DiscordAttachment) ([]string, map[string]*DiscordAttachment, bool) {
+	rest := make([]string, 0, len(keys))
+	files := make(map[string]*DiscordAttachment)
+	used := make([]bool, len(attachments))
+
+	for _, key := range keys {
+		name, kind, ok := attachmentKey(key)
+		if !ok {
+			rest = append(rest, key)
+			continue
+		}
+
+		found := false
+		for i, a := range attachments {
+			if !used[i] && a.Is(kind) {
+				used[i] = true
+				files[name] = a
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, false
+		}
+	}
+	return rest, files, true
+}
@@ -0,0 +1,142 @@
+package dgofw
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheBackend stores the raw Discord objects behind DiscordCache.
+//
+// The in-memory maps of wrapped objects are not a backend: they are always
+// the first tier of the cache, subject to its policies, and are all there is
+// by default. A backend is an optional second tier below them: everything
+// stored in the cache is written through to it, and lookups that miss memory
+// try the backend before falling back to the session state and REST.
+// Backends can be shared between processes, or persisted across restarts.
+//
+// Values are pointers to discordgo structs. ``Load`` decodes into ``v``,
+// which is a pointer to such a pointer.
+type CacheBackend interface {
+	Load(kind CacheKind, key string, v interface{}) (bool, error)
+	Store(kind CacheKind, key string, v interface{}) error
+	Delete(kind CacheKind, key string) error
+	Keys(kind CacheKind) ([]string, error)
+	Close() error
+}
+
+// MemoryBackend is a CacheBackend keeping JSON encoded objects in memory.
+type MemoryBackend struct {
+	sync.RWMutex
+	data map[CacheKind]map[string][]byte
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		data: make(map[CacheKind]map[string][]byte),
+	}
+}
+
+func (b *MemoryBackend) Load(kind CacheKind, key string, v interface{}) (bool, error) {
+	b.RLock()
+	raw, ok := b.data[kind][key]
+	b.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func (b *MemoryBackend) Store(kind CacheKind, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	b.Lock()
+	if _, ok := b.data[kind]; !ok {
+		b.data[kind] = make(map[string][]byte)
+	}
+	b.data[kind][key] = raw
+	b.Unlock()
+	return nil
+}
+
+func (b *MemoryBackend) Delete(kind CacheKind, key string) error {
+	b.Lock()
+	delete(b.data[kind], key)
+	b.Unlock()
+	return nil
+}
+
+func (b *MemoryBackend) Keys(kind CacheKind) ([]string, error) {
+	b.RLock()
+	defer b.RUnlock()
+	result := make([]string, 0, len(b.data[kind]))
+	for key := range b.data[kind] {
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+func (b *MemoryBackend) Close() error {
+	return nil
+}
+
+// SnapshotBackend is a MemoryBackend that can be saved to, and restored from, a file.
+type SnapshotBackend struct {
+	*MemoryBackend
+	path string
+}
+
+// NewSnapshotBackend creates a snapshot backend, restoring the snapshot at ``path`` if there is one.
+func NewSnapshotBackend(path string) (*SnapshotBackend, error) {
+	result := &SnapshotBackend{
+		MemoryBackend: NewMemoryBackend(),
+		path:          path,
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err = gob.NewDecoder(f).Decode(&result.data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Save writes a snapshot of the backend to disk.
+// The previous snapshot is only replaced once the new one is written completely.
+func (b *SnapshotBackend) Save() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(b.path), filepath.Base(b.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	b.RLock()
+	err = gob.NewEncoder(tmp).Encode(b.data)
+	b.RUnlock()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), b.path)
+}
+
+// Close saves a final snapshot. ``DiscordClient.Disconnect`` also saves one.
+func (b *SnapshotBackend) Close() error {
+	return b.Save()
+}
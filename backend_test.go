@@ -0,0 +1,44 @@
+package dgofw
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotBackendRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	b, err := NewSnapshotBackend(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := newTestClient()
+	c.Cache.SetBackend(b)
+	c.Cache.UpdateGuild(testGuild("1"))
+	c.Cache.UpdateChannel(testChannel("2", "1"))
+	c.Cache.UpdateMember(testMember("1", "3"))
+	c.Disconnect()
+
+	b, err = NewSnapshotBackend(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, requests := newTestClient()
+	c.Cache.SetBackend(b)
+	if err = c.Cache.Warm(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Cache.cachedGuild("1"); !ok {
+		t.Error("guild 1 wasn't restored")
+	}
+	if _, ok := c.Cache.cachedChannel("2"); !ok {
+		t.Error("channel 2 wasn't restored")
+	}
+	if m, ok := c.Cache.cachedMember("1", "3"); !ok || m.User.Username() != "user 3" {
+		t.Error("member 3 wasn't restored")
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d requests, want 0", n)
+	}
+}
@@ -0,0 +1,289 @@
+package dgofw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// AllowedMentionType is a kind of mention Discord parses out of message content.
+type AllowedMentionType string
+
+const (
+	AllowedMentionRoles    AllowedMentionType = "roles"
+	AllowedMentionUsers    AllowedMentionType = "users"
+	AllowedMentionEveryone AllowedMentionType = "everyone"
+)
+
+type (
+	// AllowedMentions controls which mentions in a message actually ping.
+	//
+	// ``Users`` and ``Roles`` whitelist specific IDs, and must not be combined
+	// with the respective type in ``Parse``.
+	AllowedMentions struct {
+		Parse       []AllowedMentionType `json:"parse"`
+		Roles       []string             `json:"roles,omitempty"`
+		Users       []string             `json:"users,omitempty"`
+		RepliedUser bool                 `json:"replied_user"`
+	}
+
+	// MessageReference points at the message being replied to.
+	MessageReference struct {
+		MessageID string `json:"message_id"`
+		ChannelID string `json:"channel_id,omitempty"`
+		GuildID   string `json:"guild_id,omitempty"`
+	}
+
+	messageSend struct {
+		Content          string                  `json:"content,omitempty"`
+		Embed            *discordgo.MessageEmbed `json:"embed,omitempty"`
+		TTS              bool                    `json:"tts"`
+		AllowedMentions  *AllowedMentions        `json:"allowed_mentions,omitempty"`
+		MessageReference *MessageReference       `json:"message_reference,omitempty"`
+	}
+
+	// MessageBuilder composes a message before sending it.
+	MessageBuilder struct {
+		client  *DiscordClient
+		channel string
+		data    messageSend
+		files   []*discordgo.File
+	}
+)
+
+// DefaultAllowedMentions lets user and role mentions ping,
+// but suppresses ``@everyone`` and ``@here``.
+var DefaultAllowedMentions = AllowedMentions{
+	Parse:       []AllowedMentionType{AllowedMentionUsers, AllowedMentionRoles},
+	RepliedUser: true,
+}
+
+func (a *AllowedMentions) copy() *AllowedMentions {
+	result := &AllowedMentions{
+		Parse:       append([]AllowedMentionType{}, a.Parse...),
+		Roles:       append([]string(nil), a.Roles...),
+		Users:       append([]string(nil), a.Users...),
+		RepliedUser: a.RepliedUser,
+	}
+	return result
+}
+
+func (a *AllowedMentions) parses(t AllowedMentionType) bool {
+	for _, p := range a.Parse {
+		if p == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AllowedMentions) without(t AllowedMentionType) {
+	for i, p := range a.Parse {
+		if p == t {
+			a.Parse = append(a.Parse[:i], a.Parse[i+1:]...)
+			return
+		}
+	}
+}
+
+// NewMessage starts building a message for a channel.
+//
+// The message uses the client's ``AllowedMentions`` policy unless overridden.
+func (c *DiscordClient) NewMessage(channel string) *MessageBuilder {
+	c.RLock()
+	policy := c.AllowedMentions
+	c.RUnlock()
+
+	return &MessageBuilder{
+		client:  c,
+		channel: channel,
+		data: messageSend{
+			AllowedMentions: policy.copy(),
+		},
+	}
+}
+
+// NewMessage starts building a message for the channel.
+func (c *DiscordChannel) NewMessage() *MessageBuilder {
+	return c.client.NewMessage(c.ID())
+}
+
+// NewReply starts building a reply referencing the message.
+func (m *DiscordMessage) NewReply() *MessageBuilder {
+	b := m.client.NewMessage(m.ChannelID())
+	return b.ReplyTo(m, b.data.AllowedMentions.RepliedUser)
+}
+
+// Content sets the text content.
+func (b *MessageBuilder) Content(content string) *MessageBuilder {
+	b.data.Content = content
+	return b
+}
+
+// Contentf sets the text content using a format string.
+func (b *MessageBuilder) Contentf(format string, args ...interface{}) *MessageBuilder {
+	return b.Content(fmt.Sprintf(format, args...))
+}
+
+// Embed sets the embed.
+func (b *MessageBuilder) Embed(embed *discordgo.MessageEmbed) *MessageBuilder {
+	b.data.Embed = embed
+	return b
+}
+
+// File attaches a file.
+func (b *MessageBuilder) File(name string, r io.Reader) *MessageBuilder {
+	b.files = append(b.files, &discordgo.File{
+		Name:   name,
+		Reader: r,
+	})
+	return b
+}
+
+// TTS sets whether the message is read out loud.
+func (b *MessageBuilder) TTS(tts bool) *MessageBuilder {
+	b.data.TTS = tts
+	return b
+}
+
+// ReplyTo makes the message a reply to ``m``.
+// ``ping`` decides whether the author of ``m`` is mentioned.
+func (b *MessageBuilder) ReplyTo(m *DiscordMessage, ping bool) *MessageBuilder {
+	b.data.MessageReference = &MessageReference{
+		MessageID: m.ID(),
+		ChannelID: m.ChannelID(),
+	}
+	b.data.AllowedMentions.RepliedUser = ping
+	return b
+}
+
+// Ping sets whether the author of the referenced message is mentioned.
+func (b *MessageBuilder) Ping(ping bool) *MessageBuilder {
+	b.data.AllowedMentions.RepliedUser = ping
+	return b
+}
+
+// AllowedMentions replaces the allowed mentions policy of the message.
+func (b *MessageBuilder) AllowedMentions(allowed AllowedMentions) *MessageBuilder {
+	b.data.AllowedMentions = allowed.copy()
+	return b
+}
+
+// AllowEveryone lets ``@everyone`` and ``@here`` ping.
+func (b *MessageBuilder) AllowEveryone() *MessageBuilder {
+	if !b.data.AllowedMentions.parses(AllowedMentionEveryone) {
+		b.data.AllowedMentions.Parse = append(b.data.AllowedMentions.Parse, AllowedMentionEveryone)
+	}
+	return b
+}
+
+// AllowUsers only lets the given users be pinged.
+func (b *MessageBuilder) AllowUsers(ids ...string) *MessageBuilder {
+	b.data.AllowedMentions.without(AllowedMentionUsers)
+	b.data.AllowedMentions.Users = ids
+	return b
+}
+
+// AllowRoles only lets the given roles be pinged.
+func (b *MessageBuilder) AllowRoles(ids ...string) *MessageBuilder {
+	b.data.AllowedMentions.without(AllowedMentionRoles)
+	b.data.AllowedMentions.Roles = ids
+	return b
+}
+
+// AllowNone suppresses every mention in the message.
+func (b *MessageBuilder) AllowNone() *MessageBuilder {
+	b.data.AllowedMentions = &AllowedMentions{
+		Parse: []AllowedMentionType{},
+	}
+	return b
+}
+
+// Send sends the message.
+func (b *MessageBuilder) Send() (*DiscordMessage, error) {
+	if b.data.Embed != nil && b.data.Embed.Type == "" {
+		b.data.Embed.Type = "rich"
+	}
+
+	endpoint := discordgo.EndpointChannelMessages(b.channel)
+	ses := b.client.ses
+
+	var (
+		response []byte
+		err      error
+	)
+	if len(b.files) > 0 {
+		var (
+			body        []byte
+			contentType string
+		)
+		body, contentType, err = b.multipart()
+		if err != nil {
+			return nil, err
+		}
+		response, err = ses.RequestWithLockedBucket("POST", endpoint, contentType, body, ses.Ratelimiter.LockBucket(endpoint), 0)
+	} else {
+		response, err = ses.RequestWithBucketID("POST", endpoint, b.data, endpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m *discordgo.Message
+	if err = json.Unmarshal(response, &m); err != nil {
+		return nil, err
+	}
+	return NewDiscordMessage(b.client, m), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (b *MessageBuilder) multipart() ([]byte, string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	payload, err := json.Marshal(b.data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="payload_json"`)
+	h.Set("Content-Type", "application/json")
+	p, err := w.CreatePart(h)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err = p.Write(payload); err != nil {
+		return nil, "", err
+	}
+
+	for i, file := range b.files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file%d"; filename="%s"`, i, quoteEscaper.Replace(file.Name)))
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h.Set("Content-Type", contentType)
+
+		p, err = w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err = io.Copy(p, file.Reader); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), w.FormDataContentType(), nil
+}
@@ -0,0 +1,53 @@
+package dgofw
+
+import "sync"
+
+type (
+	// eventBus dispatches events the framework derives itself, which discordgo
+	// doesn't know about, and events it caches before the user's handlers run.
+	eventBus struct {
+		sync.RWMutex
+		handlers map[string][]*busHandler
+	}
+
+	busHandler struct {
+		once bool
+		cb   func(interface{})
+	}
+)
+
+func (b *eventBus) add(event string, once bool, cb func(interface{})) {
+	b.Lock()
+	if b.handlers == nil {
+		b.handlers = make(map[string][]*busHandler)
+	}
+	b.handlers[event] = append(b.handlers[event], &busHandler{
+		once: once,
+		cb:   cb,
+	})
+	b.Unlock()
+}
+
+// emit calls every handler of an event in its own goroutine, like discordgo does,
+// and reports whether there were any.
+func (b *eventBus) emit(event string, v interface{}) bool {
+	b.Lock()
+	handlers, ok := b.handlers[event]
+	if !ok || len(handlers) == 0 {
+		b.Unlock()
+		return false
+	}
+	keep := make([]*busHandler, 0, len(handlers))
+	for _, h := range handlers {
+		if !h.once {
+			keep = append(keep, h)
+		}
+	}
+	b.handlers[event] = keep
+	b.Unlock()
+
+	for _, h := range handlers {
+		go h.cb(v)
+	}
+	return true
+}
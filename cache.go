@@ -0,0 +1,720 @@
+package dgofw
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DiscordCache caches wrapped Discord objects.
+//
+// It is safe for concurrent use. Lookups that miss the cache fall back
+// to the session state, and then to the REST API. Whatever is fetched is
+// stored in the cache, and concurrent misses for the same entity share one request.
+//
+// By default nothing is ever evicted, see ``SetPolicy`` to limit the cache,
+// and nothing is kept outside of memory, see ``SetBackend``.
+type DiscordCache struct {
+	sync.RWMutex
+	client      *DiscordClient
+	users       *entityStore
+	members     *entityStore                   // keyed by memberKey
+	memberIndex map[string]map[string]struct{} // guild ID -> user IDs
+	guilds      *entityStore
+	channels    *entityStore
+	flight      flightGroup
+	backend     CacheBackend
+}
+
+func (c *DiscordClient) initCache() {
+	c.Cache = &DiscordCache{
+		client:      c,
+		users:       newEntityStore(),
+		members:     newEntityStore(),
+		memberIndex: make(map[string]map[string]struct{}),
+		guilds:      newEntityStore(),
+		channels:    newEntityStore(),
+	}
+	c.Cache.members.onEvict = func(_ string, v interface{}) {
+		m := v.(*DiscordMember)
+		c.Cache.unindexMember(m.GuildID(), m.User.ID())
+	}
+}
+
+func memberKey(guild, id string) string {
+	return guild + ":" + id
+}
+
+func (c *DiscordCache) store(kind CacheKind) *entityStore {
+	switch kind {
+	case CacheUsers:
+		return c.users
+	case CacheMembers:
+		return c.members
+	case CacheGuilds:
+		return c.guilds
+	case CacheChannels:
+		return c.channels
+	}
+	return nil
+}
+
+// SetPolicy sets the eviction policy of an entity type.
+// Entities that don't fit the new policy are evicted right away.
+func (c *DiscordCache) SetPolicy(kind CacheKind, p CachePolicy) {
+	c.Lock()
+	defer c.Unlock()
+	if s := c.store(kind); s != nil {
+		s.setPolicy(p)
+	}
+}
+
+// Policy returns the eviction policy of an entity type.
+func (c *DiscordCache) Policy(kind CacheKind) CachePolicy {
+	c.RLock()
+	defer c.RUnlock()
+	if s := c.store(kind); s != nil {
+		return s.policy
+	}
+	return CachePolicy{}
+}
+
+// Stats returns the hit, miss and eviction counters and the size of an entity type.
+func (c *DiscordCache) Stats(kind CacheKind) CacheStats {
+	c.RLock()
+	defer c.RUnlock()
+	if s := c.store(kind); s != nil {
+		return s.snapshot()
+	}
+	return CacheStats{}
+}
+
+// Purge evicts all expired entities.
+//
+// Expired entities are also dropped when they are looked up, so calling
+// this is only needed to free memory early.
+func (c *DiscordCache) Purge() {
+	c.Lock()
+	defer c.Unlock()
+	for _, s := range []*entityStore{c.users, c.members, c.guilds, c.channels} {
+		s.purge()
+	}
+}
+
+// SetBackend sets the backend the cache writes through to, see ``CacheBackend``.
+// Passing nil keeps the cache in memory only, which is the default.
+func (c *DiscordCache) SetBackend(b CacheBackend) {
+	c.Lock()
+	c.backend = b
+	c.Unlock()
+}
+
+// Backend returns the backend of the cache, or nil.
+func (c *DiscordCache) Backend() CacheBackend {
+	c.RLock()
+	defer c.RUnlock()
+	return c.backend
+}
+
+// load looks up an entity in the backend. ``v`` is a pointer to a discordgo struct pointer.
+func (c *DiscordCache) load(kind CacheKind, key string, v interface{}) bool {
+	b := c.Backend()
+	if b == nil || c.Policy(kind).Disabled {
+		return false
+	}
+
+	ok, err := b.Load(kind, key, v)
+	if err != nil {
+		c.client.reportError(err)
+		return false
+	}
+	return ok
+}
+
+// persist writes an entity through to the backend.
+func (c *DiscordCache) persist(kind CacheKind, key string, v interface{}) {
+	b := c.Backend()
+	if b == nil || c.Policy(kind).Disabled {
+		return
+	}
+
+	if err := b.Store(kind, key, v); err != nil {
+		c.client.reportError(err)
+	}
+}
+
+// forget removes an entity from the backend.
+func (c *DiscordCache) forget(kind CacheKind, key string) {
+	b := c.Backend()
+	if b == nil {
+		return
+	}
+
+	if err := b.Delete(kind, key); err != nil {
+		c.client.reportError(err)
+	}
+}
+
+// Warm loads every user, guild, channel and member in the backend into memory,
+// e.g. after restoring a snapshot on startup.
+func (c *DiscordCache) Warm() error {
+	b := c.Backend()
+	if b == nil {
+		return nil
+	}
+
+	for _, kind := range []CacheKind{CacheUsers, CacheGuilds, CacheChannels, CacheMembers} {
+		keys, err := b.Keys(kind)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			switch kind {
+			case CacheUsers:
+				var u *discordgo.User
+				if c.load(kind, key, &u) && u != nil {
+					c.updateUser(u)
+				}
+			case CacheGuilds:
+				var g *discordgo.Guild
+				if c.load(kind, key, &g) && g != nil {
+					c.updateGuild(g)
+				}
+			case CacheChannels:
+				var ch *discordgo.Channel
+				if c.load(kind, key, &ch) && ch != nil {
+					c.updateChannel(ch)
+				}
+			case CacheMembers:
+				var m *discordgo.Member
+				if c.load(kind, key, &m) && m != nil && m.User != nil {
+					c.updateMember(m)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// refresh looks up an entity that is about to be updated. It marks the entity
+// as recently used and restarts its TTL, without counting a hit.
+func (c *DiscordCache) refresh(s *entityStore, key string) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	v, ok := s.peek(key)
+	if ok {
+		s.put(key, v)
+	}
+	return v, ok
+}
+
+func (c *DiscordCache) cachedUser(id string) (*DiscordUser, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if u, ok := c.users.get(id); ok {
+		return u.(*DiscordUser), true
+	}
+	return nil, false
+}
+
+// GetUser gets a user from the cache
+func (c *DiscordCache) GetUser(id string) *DiscordUser {
+	if u, ok := c.cachedUser(id); ok {
+		return u
+	}
+
+	res, err := c.flight.do("user:"+id, func() (interface{}, error) {
+		var cached *discordgo.User
+		if c.load(CacheUsers, id, &cached) && cached != nil {
+			u, _ := c.updateUser(cached)
+			return u, nil
+		}
+
+		if u := c.client.ses.State.User; u != nil && u.ID == id {
+			return c.UpdateUser(u), nil
+		}
+
+		u, err := c.client.ses.User(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.UpdateUser(u), nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	return res.(*DiscordUser)
+}
+
+// UpdateUser stores a user in the cache, updating the cached wrapper in place if there is one.
+//
+// Partial users, as sent in presence updates, don't replace cached data.
+// Users are only written to the backend when they changed, since this runs for every message.
+func (c *DiscordCache) UpdateUser(u *discordgo.User) *DiscordUser {
+	u = copyUser(u)
+	result, changed := c.updateUser(u)
+	if changed {
+		c.persist(CacheUsers, u.ID, u)
+	}
+	return result
+}
+
+// updateUser reports whether the user is new to the cache, or differs from the cached one.
+func (c *DiscordCache) updateUser(u *discordgo.User) (*DiscordUser, bool) {
+	if cu, ok := c.refresh(c.users, u.ID); ok {
+		return cu.(*DiscordUser), cu.(*DiscordUser).update(u)
+	}
+
+	result := NewDiscordUser(c.client, u)
+
+	c.Lock()
+	if cu, ok := c.users.peek(u.ID); ok {
+		c.Unlock()
+		return cu.(*DiscordUser), cu.(*DiscordUser).update(u)
+	}
+	c.users.put(u.ID, result)
+	c.Unlock()
+	return result, u.Username != ""
+}
+
+func (c *DiscordCache) DeleteUser(id string) {
+	c.Lock()
+	c.users.remove(id)
+	c.Unlock()
+	c.forget(CacheUsers, id)
+}
+
+func (c *DiscordCache) cachedGuild(id string) (*DiscordGuild, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if g, ok := c.guilds.get(id); ok {
+		return g.(*DiscordGuild), true
+	}
+	return nil, false
+}
+
+// GetGuild gets a guild from the cache
+func (c *DiscordCache) GetGuild(id string) *DiscordGuild {
+	if g, ok := c.cachedGuild(id); ok {
+		return g
+	}
+
+	res, err := c.flight.do("guild:"+id, func() (interface{}, error) {
+		var cached *discordgo.Guild
+		if c.load(CacheGuilds, id, &cached) && cached != nil {
+			return c.updateGuild(cached), nil
+		}
+
+		if g, err := c.client.ses.State.Guild(id); err == nil {
+			return c.UpdateGuild(g), nil
+		}
+
+		g, err := c.client.ses.Guild(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.UpdateGuild(g), nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	return res.(*DiscordGuild)
+}
+
+// UpdateGuild stores a guild in the cache, updating the cached wrapper in place if there is one.
+func (c *DiscordCache) UpdateGuild(g *discordgo.Guild) *DiscordGuild {
+	g = copyGuild(g)
+	result := c.updateGuild(g)
+	c.persist(CacheGuilds, g.ID, g)
+	return result
+}
+
+func (c *DiscordCache) updateGuild(g *discordgo.Guild) *DiscordGuild {
+	if gc, ok := c.refresh(c.guilds, g.ID); ok {
+		gc.(*DiscordGuild).set(g)
+		return gc.(*DiscordGuild)
+	}
+
+	// The wrapper is built outside the lock, since it looks up other cached objects.
+	result := NewDiscordGuild(c.client, g)
+
+	c.Lock()
+	if gc, ok := c.guilds.peek(g.ID); ok {
+		c.Unlock()
+		gc.(*DiscordGuild).set(g)
+		return gc.(*DiscordGuild)
+	}
+	c.guilds.put(g.ID, result)
+	c.Unlock()
+	return result
+}
+
+// DeleteGuild removes a guild, and all of its members, from the cache.
+func (c *DiscordCache) DeleteGuild(id string) {
+	c.Lock()
+	c.guilds.remove(id)
+	users := c.memberIndex[id]
+	for user := range users {
+		c.members.remove(memberKey(id, user))
+	}
+	delete(c.memberIndex, id)
+	c.Unlock()
+
+	c.forget(CacheGuilds, id)
+	for user := range users {
+		c.forget(CacheMembers, memberKey(id, user))
+	}
+}
+
+func (c *DiscordCache) cachedChannel(id string) (*DiscordChannel, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if ch, ok := c.channels.get(id); ok {
+		return ch.(*DiscordChannel), true
+	}
+	return nil, false
+}
+
+// GetChannel gets a channel from the cache
+func (c *DiscordCache) GetChannel(id string) *DiscordChannel {
+	if ch, ok := c.cachedChannel(id); ok {
+		return ch
+	}
+
+	res, err := c.flight.do("channel:"+id, func() (interface{}, error) {
+		var cached *discordgo.Channel
+		if c.load(CacheChannels, id, &cached) && cached != nil {
+			return c.updateChannel(cached), nil
+		}
+
+		if ch, err := c.client.ses.State.Channel(id); err == nil {
+			return c.UpdateChannel(ch), nil
+		}
+
+		ch, err := c.client.ses.Channel(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.UpdateChannel(ch), nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	return res.(*DiscordChannel)
+}
+
+// UpdateChannel stores a channel in the cache, updating the cached wrapper in place if there is one.
+func (c *DiscordCache) UpdateChannel(ch *discordgo.Channel) *DiscordChannel {
+	ch = copyChannel(ch)
+	result := c.updateChannel(ch)
+	c.persist(CacheChannels, ch.ID, ch)
+	return result
+}
+
+func (c *DiscordCache) updateChannel(ch *discordgo.Channel) *DiscordChannel {
+	if cc, ok := c.refresh(c.channels, ch.ID); ok {
+		cc.(*DiscordChannel).set(ch)
+		return cc.(*DiscordChannel)
+	}
+
+	result := NewDiscordChannel(c.client, ch)
+
+	c.Lock()
+	if cc, ok := c.channels.peek(ch.ID); ok {
+		c.Unlock()
+		cc.(*DiscordChannel).set(ch)
+		return cc.(*DiscordChannel)
+	}
+	c.channels.put(ch.ID, result)
+	c.Unlock()
+	return result
+}
+
+func (c *DiscordCache) DeleteChannel(id string) {
+	c.Lock()
+	c.channels.remove(id)
+	c.Unlock()
+	c.forget(CacheChannels, id)
+}
+
+// Channels returns all cached channels of a guild, including threads.
+func (c *DiscordCache) Channels(guild string) []*DiscordChannel {
+	c.RLock()
+	values := c.channels.values()
+	c.RUnlock()
+
+	result := make([]*DiscordChannel, 0)
+	for _, v := range values {
+		if ch := v.(*DiscordChannel); ch.GuildID() == guild {
+			result = append(result, ch)
+		}
+	}
+	return result
+}
+
+func (c *DiscordCache) cachedMember(guild, id string) (*DiscordMember, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if m, ok := c.members.get(memberKey(guild, id)); ok {
+		return m.(*DiscordMember), true
+	}
+	return nil, false
+}
+
+// unindexMember must be called with the lock held.
+func (c *DiscordCache) unindexMember(guild, id string) {
+	if members, ok := c.memberIndex[guild]; ok {
+		delete(members, id)
+		if len(members) == 0 {
+			delete(c.memberIndex, guild)
+		}
+	}
+}
+
+// GetMember gets a member of a guild from the cache
+func (c *DiscordCache) GetMember(guild, id string) *DiscordMember {
+	if m, ok := c.cachedMember(guild, id); ok {
+		return m
+	}
+
+	res, err := c.flight.do("member:"+memberKey(guild, id), func() (interface{}, error) {
+		var cached *discordgo.Member
+		if c.load(CacheMembers, memberKey(guild, id), &cached) && cached != nil {
+			return c.updateMember(cached), nil
+		}
+
+		if m, err := c.client.ses.State.Member(guild, id); err == nil {
+			return c.UpdateMember(m), nil
+		}
+
+		m, err := c.client.ses.GuildMember(guild, id)
+		if err != nil {
+			return nil, err
+		}
+
+		// Members fetched over REST don't carry their guild.
+		m.GuildID = guild
+		return c.UpdateMember(m), nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	return res.(*DiscordMember)
+}
+
+// UpdateMember stores a member in the cache, updating the cached wrapper in place if there is one.
+func (c *DiscordCache) UpdateMember(m *discordgo.Member) *DiscordMember {
+	m = copyMember(m)
+	c.UpdateUser(m.User)
+	result := c.updateMember(m)
+	c.persist(CacheMembers, memberKey(m.GuildID, m.User.ID), m)
+	return result
+}
+
+func (c *DiscordCache) updateMember(m *discordgo.Member) *DiscordMember {
+	if cm, ok := c.refresh(c.members, memberKey(m.GuildID, m.User.ID)); ok {
+		c.updateUser(m.User)
+		cm.(*DiscordMember).set(m)
+		return cm.(*DiscordMember)
+	}
+
+	result := NewDiscordMember(c.client, m)
+
+	c.Lock()
+	if cm, ok := c.members.peek(memberKey(m.GuildID, m.User.ID)); ok {
+		c.Unlock()
+		c.updateUser(m.User)
+		cm.(*DiscordMember).set(m)
+		return cm.(*DiscordMember)
+	}
+	if !c.members.policy.Disabled {
+		c.members.put(memberKey(m.GuildID, m.User.ID), result)
+		if _, ok := c.memberIndex[m.GuildID]; !ok {
+			c.memberIndex[m.GuildID] = make(map[string]struct{})
+		}
+		c.memberIndex[m.GuildID][m.User.ID] = struct{}{}
+	}
+	c.Unlock()
+	return result
+}
+
+// DeleteMember removes a member of a guild from the cache.
+// The user's memberships in other guilds are kept.
+func (c *DiscordCache) DeleteMember(guild, id string) {
+	c.Lock()
+	c.members.remove(memberKey(guild, id))
+	c.unindexMember(guild, id)
+	c.Unlock()
+	c.forget(CacheMembers, memberKey(guild, id))
+}
+
+// Members returns all cached members of a guild.
+func (c *DiscordCache) Members(guild string) []*DiscordMember {
+	c.Lock()
+	defer c.Unlock()
+
+	members := c.memberIndex[guild]
+	result := make([]*DiscordMember, 0, len(members))
+	for user := range members {
+		// Expired members are evicted, which also drops them from the index.
+		if m, ok := c.members.live(memberKey(guild, user)); ok {
+			result = append(result, m.(*DiscordMember))
+		}
+	}
+	return result
+}
+
+// MemberCount returns the amount of cached members of a guild.
+func (c *DiscordCache) MemberCount(guild string) int {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.members.policy.TTL > 0 {
+		for user := range c.memberIndex[guild] {
+			c.members.live(memberKey(guild, user))
+		}
+	}
+	return len(c.memberIndex[guild])
+}
+
+// EachMember calls ``fn`` for every cached member of a guild, until ``fn`` returns false.
+//
+// ``fn`` is called on a snapshot, so it may use the cache itself.
+func (c *DiscordCache) EachMember(guild string, fn func(*DiscordMember) bool) {
+	for _, m := range c.Members(guild) {
+		if !fn(m) {
+			return
+		}
+	}
+}
+
+// MemberGuilds returns the IDs of all guilds a user is cached as a member of.
+func (c *DiscordCache) MemberGuilds(id string) []string {
+	c.Lock()
+	defer c.Unlock()
+
+	result := make([]string, 0)
+	for guild, members := range c.memberIndex {
+		if _, ok := members[id]; !ok {
+			continue
+		}
+		if _, ok := c.members.live(memberKey(guild, id)); ok {
+			result = append(result, guild)
+		}
+	}
+	return result
+}
+
+// The session state updates the objects it holds in place, without the cache's locks.
+// Everything is copied before it is cached, so the cache never shares an object with it.
+
+func copyUser(u *discordgo.User) *discordgo.User {
+	if u == nil {
+		return nil
+	}
+	result := *u
+	return &result
+}
+
+func copyMember(m *discordgo.Member) *discordgo.Member {
+	result := *m
+	result.User = copyUser(m.User)
+	result.Roles = append([]string(nil), m.Roles...)
+	return &result
+}
+
+func copyRole(r *discordgo.Role) *discordgo.Role {
+	result := *r
+	return &result
+}
+
+func copyChannel(ch *discordgo.Channel) *discordgo.Channel {
+	result := *ch
+	result.Messages = nil
+	result.Members = nil
+
+	result.Recipients = make([]*discordgo.User, len(ch.Recipients))
+	for i, u := range ch.Recipients {
+		result.Recipients[i] = copyUser(u)
+	}
+
+	result.PermissionOverwrites = make([]*discordgo.PermissionOverwrite, len(ch.PermissionOverwrites))
+	for i, o := range ch.PermissionOverwrites {
+		o2 := *o
+		result.PermissionOverwrites[i] = &o2
+	}
+
+	if ch.ThreadMetadata != nil {
+		m := *ch.ThreadMetadata
+		result.ThreadMetadata = &m
+	}
+	if ch.Member != nil {
+		m := *ch.Member
+		result.Member = &m
+	}
+	result.AvailableTags = append([]discordgo.ForumTag(nil), ch.AvailableTags...)
+	result.AppliedTags = append([]string(nil), ch.AppliedTags...)
+	return &result
+}
+
+func copyGuild(g *discordgo.Guild) *discordgo.Guild {
+	result := *g
+
+	result.Roles = make([]*discordgo.Role, len(g.Roles))
+	for i, r := range g.Roles {
+		result.Roles[i] = copyRole(r)
+	}
+
+	result.Emojis = make([]*discordgo.Emoji, len(g.Emojis))
+	for i, e := range g.Emojis {
+		e2 := *e
+		e2.User = copyUser(e.User)
+		e2.Roles = append([]string(nil), e.Roles...)
+		result.Emojis[i] = &e2
+	}
+
+	result.Members = make([]*discordgo.Member, len(g.Members))
+	for i, m := range g.Members {
+		result.Members[i] = copyMember(m)
+	}
+
+	result.Presences = make([]*discordgo.Presence, len(g.Presences))
+	for i, p := range g.Presences {
+		p2 := *p
+		p2.User = copyUser(p.User)
+		p2.Activities = append([]*discordgo.Activity(nil), p.Activities...)
+		result.Presences[i] = &p2
+	}
+
+	result.Channels = make([]*discordgo.Channel, len(g.Channels))
+	for i, ch := range g.Channels {
+		result.Channels[i] = copyChannel(ch)
+	}
+
+	result.Threads = make([]*discordgo.Channel, len(g.Threads))
+	for i, t := range g.Threads {
+		result.Threads[i] = copyChannel(t)
+	}
+
+	result.VoiceStates = make([]*discordgo.VoiceState, len(g.VoiceStates))
+	for i, v := range g.VoiceStates {
+		v2 := *v
+		if v.Member != nil {
+			v2.Member = copyMember(v.Member)
+		}
+		result.VoiceStates[i] = &v2
+	}
+
+	result.Stickers = append([]*discordgo.Sticker(nil), g.Stickers...)
+	result.StageInstances = append([]*discordgo.StageInstance(nil), g.StageInstances...)
+	result.Features = append([]discordgo.GuildFeature(nil), g.Features...)
+	return &result
+}
@@ -0,0 +1,293 @@
+package dgofw
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// failingTransport fails every request it gets, and counts them.
+type failingTransport struct {
+	requests int64
+}
+
+func (t *failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&t.requests, 1)
+	return nil, errors.New("no requests allowed in tests")
+}
+
+func (t *failingTransport) count() int64 {
+	return atomic.LoadInt64(&t.requests)
+}
+
+// newTestClient returns a client that never reaches Discord.
+func newTestClient() (*DiscordClient, *failingTransport) {
+	c := NewDiscordClient("test")
+	t := new(failingTransport)
+	c.ses.Client = &http.Client{Transport: t}
+	c.ses.MaxRestRetries = 0
+	return c, t
+}
+
+func testGuild(id string) *discordgo.Guild {
+	return &discordgo.Guild{
+		ID:      id,
+		Name:    "guild " + id,
+		OwnerID: "owner",
+	}
+}
+
+func testChannel(id, guild string) *discordgo.Channel {
+	return &discordgo.Channel{
+		ID:      id,
+		GuildID: guild,
+		Name:    "channel " + id,
+		Type:    discordgo.ChannelTypeGuildText,
+	}
+}
+
+func testMember(guild, id string) *discordgo.Member {
+	return &discordgo.Member{
+		GuildID: guild,
+		User: &discordgo.User{
+			ID:       id,
+			Username: "user " + id,
+		},
+	}
+}
+
+const (
+	churnGuilds  = 4
+	churnUsers   = 50
+	churnWorkers = 8
+	churnRounds  = 200
+)
+
+// churn runs writers and readers against the cache at the same time.
+// Run with ``go test -race``.
+func churn(t *testing.T, c *DiscordClient) {
+	var wg sync.WaitGroup
+	for w := 0; w < churnWorkers; w++ {
+		wg.Add(2)
+
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < churnRounds; i++ {
+				guild := fmt.Sprint(i % churnGuilds)
+				user := fmt.Sprint((i + w) % churnUsers)
+
+				g := testGuild(guild)
+				g.Name = fmt.Sprintf("guild %s round %d", guild, i)
+				c.Cache.UpdateGuild(g)
+				c.Cache.UpdateChannel(testChannel("c"+guild, guild))
+
+				if i%3 == 0 {
+					c.Cache.DeleteMember(guild, user)
+				} else {
+					c.Cache.UpdateMember(testMember(guild, user))
+				}
+			}
+		}(w)
+
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < churnRounds; i++ {
+				guild := fmt.Sprint(i % churnGuilds)
+				user := fmt.Sprint((i + w) % churnUsers)
+
+				if g, ok := c.Cache.cachedGuild(guild); ok && g.ID() != guild {
+					t.Errorf("got guild %s, want %s", g.ID(), guild)
+				}
+				if ch, ok := c.Cache.cachedChannel("c" + guild); ok {
+					if ch.GuildID() != guild {
+						t.Errorf("got channel in guild %s, want %s", ch.GuildID(), guild)
+					}
+					ch.Name()
+				}
+				if m, ok := c.Cache.cachedMember(guild, user); ok && m.GuildID() != guild {
+					t.Errorf("got member of guild %s, want %s", m.GuildID(), guild)
+				}
+				for _, m := range c.Cache.Members(guild) {
+					if m.GuildID() != guild {
+						t.Errorf("got member of guild %s in members of %s", m.GuildID(), guild)
+					}
+					m.User.Username()
+				}
+				c.Cache.MemberCount(guild)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestCacheChurn(t *testing.T) {
+	c, _ := newTestClient()
+	churn(t, c)
+
+	for g := 0; g < churnGuilds; g++ {
+		guild := fmt.Sprint(g)
+		if n, want := c.Cache.MemberCount(guild), len(c.Cache.Members(guild)); n != want {
+			t.Errorf("guild %s: member count %d, but %d members", guild, n, want)
+		}
+	}
+}
+
+func TestCacheChurnWithPolicies(t *testing.T) {
+	c, _ := newTestClient()
+	c.Cache.SetPolicy(CacheMembers, CachePolicy{MaxSize: churnUsers})
+	c.Cache.SetPolicy(CacheUsers, CachePolicy{TTL: time.Millisecond})
+	c.Cache.SetPolicy(CacheChannels, CachePolicy{MaxSize: 1})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < churnRounds; i++ {
+			c.Cache.Purge()
+			c.Cache.Stats(CacheMembers)
+		}
+	}()
+	churn(t, c)
+	<-done
+
+	if s := c.Cache.Stats(CacheMembers); s.Size > churnUsers {
+		t.Errorf("got %d members, limit is %d", s.Size, churnUsers)
+	}
+}
+
+func TestCacheGetters(t *testing.T) {
+	c, _ := newTestClient()
+	c.Cache.UpdateGuild(testGuild("1"))
+	c.Cache.UpdateChannel(testChannel("2", "1"))
+	c.Cache.UpdateMember(testMember("1", "3"))
+
+	var wg sync.WaitGroup
+	for w := 0; w < churnWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < churnRounds; i++ {
+				if g := c.Cache.GetGuild("1"); g == nil || g.ID() != "1" {
+					t.Error("guild 1 not found")
+				}
+				if ch := c.Cache.GetChannel("2"); ch == nil || ch.ID() != "2" {
+					t.Error("channel 2 not found")
+				}
+				if m := c.Cache.GetMember("1", "3"); m == nil || m.User.ID() != "3" {
+					t.Error("member 3 not found")
+				}
+				c.Cache.UpdateMember(testMember("1", "3"))
+				c.Cache.UpdateGuild(testGuild("1"))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCacheMembersPerGuild(t *testing.T) {
+	c, _ := newTestClient()
+	a := testMember("1", "3")
+	a.Nick = "a"
+	b := testMember("2", "3")
+	b.Nick = "b"
+	c.Cache.UpdateMember(a)
+	c.Cache.UpdateMember(b)
+
+	c.Cache.DeleteMember("1", "3")
+	if _, ok := c.Cache.cachedMember("1", "3"); ok {
+		t.Error("member of guild 1 wasn't deleted")
+	}
+	if m, ok := c.Cache.cachedMember("2", "3"); !ok || m.Nickname() != "b" {
+		t.Error("member of guild 2 was lost")
+	}
+}
+
+func TestCacheMemberTTL(t *testing.T) {
+	c, _ := newTestClient()
+	c.Cache.SetPolicy(CacheMembers, CachePolicy{TTL: 20 * time.Millisecond})
+
+	for i := 0; i < 5; i++ {
+		c.Cache.UpdateMember(testMember("1", fmt.Sprint(i)))
+	}
+	time.Sleep(30 * time.Millisecond)
+	c.Cache.UpdateMember(testMember("1", "5"))
+
+	if n := c.Cache.MemberCount("1"); n != 1 {
+		t.Errorf("got member count %d, want 1", n)
+	}
+	if n := len(c.Cache.Members("1")); n != 1 {
+		t.Errorf("got %d members, want 1", n)
+	}
+	if guilds := c.Cache.MemberGuilds("0"); len(guilds) != 0 {
+		t.Errorf("expired member is still in guilds %v", guilds)
+	}
+}
+
+// TestCacheStateRace feeds events through the session state while the cache
+// is read. The state updates the objects it holds in place, so the cache must not share them.
+func TestCacheStateRace(t *testing.T) {
+	c, _ := newTestClient()
+	st := c.ses.State
+	if err := st.GuildAdd(testGuild("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.ChannelAdd(testChannel("2", "1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Like the member add handler, the first event is cached as it arrives.
+	m := testMember("1", "3")
+	st.OnInterface(c.ses, &discordgo.GuildMemberAdd{Member: m})
+	c.Cache.UpdateMember(m)
+
+	// These fall back to the state.
+	c.Cache.GetGuild("1")
+	c.Cache.GetChannel("2")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			m := testMember("1", "3")
+			m.Nick = fmt.Sprint("nick ", i)
+			m.Roles = []string{fmt.Sprint(i)}
+			st.OnInterface(c.ses, &discordgo.GuildMemberAdd{Member: m})
+
+			g := testGuild("1")
+			g.Name = fmt.Sprint("guild ", i)
+			st.OnInterface(c.ses, &discordgo.GuildUpdate{Guild: g})
+
+			ch := testChannel("2", "1")
+			ch.Topic = fmt.Sprint("topic ", i)
+			st.OnInterface(c.ses, &discordgo.ChannelUpdate{Channel: ch})
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		for _, m := range c.Cache.Members("1") {
+			m.Nickname()
+			m.RoleIDs()
+			m.User.Username()
+		}
+		c.Cache.GetGuild("1").Name()
+		c.Cache.GetChannel("2").Topic()
+	}
+
+	sm, _ := st.Member("1", "3")
+	sg, _ := st.Guild("1")
+	sc, _ := st.Channel("2")
+	if c.Cache.GetMember("1", "3").raw() == sm || c.Cache.GetGuild("1").raw() == sg || c.Cache.GetChannel("2").raw() == sc {
+		t.Error("cache shares objects with the state")
+	}
+}
@@ -0,0 +1,215 @@
+package dgofw
+
+import (
+	"container/list"
+	"time"
+)
+
+// CacheKind is a type of entity held in the cache.
+type CacheKind int
+
+const (
+	CacheUsers CacheKind = iota
+	CacheMembers
+	CacheGuilds
+	CacheChannels
+)
+
+func (k CacheKind) String() string {
+	switch k {
+	case CacheUsers:
+		return "users"
+	case CacheMembers:
+		return "members"
+	case CacheGuilds:
+		return "guilds"
+	case CacheChannels:
+		return "channels"
+	}
+	return "unknown"
+}
+
+type (
+	// CachePolicy limits what is kept in the cache for an entity type.
+	CachePolicy struct {
+		// Disabled turns off caching of the entity type entirely.
+		Disabled bool
+
+		// MaxSize is the maximum amount of entities kept.
+		// The least recently used entity is evicted first. Zero means unlimited.
+		MaxSize int
+
+		// TTL is how long an entity is kept after it was last stored.
+		// Zero means entities never expire.
+		TTL time.Duration
+	}
+
+	// CacheStats are counters for one entity type in the cache.
+	CacheStats struct {
+		Hits      uint64
+		Misses    uint64
+		Evictions uint64
+		Size      int
+	}
+
+	// entityStore is a map with LRU and TTL eviction. It is not safe
+	// for concurrent use; DiscordCache guards it.
+	entityStore struct {
+		policy  CachePolicy
+		items   map[string]*list.Element
+		order   *list.List // Front is the most recently used.
+		stats   CacheStats
+		onEvict func(key string, value interface{})
+	}
+
+	storeEntry struct {
+		key     string
+		value   interface{}
+		expires time.Time
+	}
+)
+
+func newEntityStore() *entityStore {
+	return &entityStore{
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+func (s *entityStore) expired(e *storeEntry) bool {
+	return !e.expires.IsZero() && time.Now().After(e.expires)
+}
+
+// get looks up a key, and marks it as recently used.
+func (s *entityStore) get(key string) (interface{}, bool) {
+	el, ok := s.items[key]
+	if !ok {
+		s.stats.Misses++
+		return nil, false
+	}
+
+	e := el.Value.(*storeEntry)
+	if s.expired(e) {
+		s.evict(el)
+		s.stats.Misses++
+		return nil, false
+	}
+
+	s.order.MoveToFront(el)
+	s.stats.Hits++
+	return e.value, true
+}
+
+// peek looks up a key without touching the stats or usage order.
+func (s *entityStore) peek(key string) (interface{}, bool) {
+	el, ok := s.items[key]
+	if !ok || s.expired(el.Value.(*storeEntry)) {
+		return nil, false
+	}
+	return el.Value.(*storeEntry).value, true
+}
+
+// live looks up a key like peek, but evicts it if it expired.
+func (s *entityStore) live(key string) (interface{}, bool) {
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	if e := el.Value.(*storeEntry); s.expired(e) {
+		s.evict(el)
+		return nil, false
+	}
+	return el.Value.(*storeEntry).value, true
+}
+
+func (s *entityStore) put(key string, value interface{}) {
+	if s.policy.Disabled {
+		return
+	}
+
+	var expires time.Time
+	if s.policy.TTL > 0 {
+		expires = time.Now().Add(s.policy.TTL)
+	}
+
+	if el, ok := s.items[key]; ok {
+		e := el.Value.(*storeEntry)
+		e.value, e.expires = value, expires
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&storeEntry{
+		key:     key,
+		value:   value,
+		expires: expires,
+	})
+	s.trim()
+}
+
+func (s *entityStore) remove(key string) {
+	if el, ok := s.items[key]; ok {
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+}
+
+func (s *entityStore) evict(el *list.Element) {
+	e := el.Value.(*storeEntry)
+	s.order.Remove(el)
+	delete(s.items, e.key)
+	s.stats.Evictions++
+	if s.onEvict != nil {
+		s.onEvict(e.key, e.value)
+	}
+}
+
+// trim evicts the least recently used entities until the store fits its size limit.
+func (s *entityStore) trim() {
+	for s.policy.MaxSize > 0 && s.order.Len() > s.policy.MaxSize {
+		s.evict(s.order.Back())
+	}
+}
+
+// purge evicts all expired entities.
+func (s *entityStore) purge() {
+	if s.policy.TTL <= 0 {
+		return
+	}
+
+	for el := s.order.Back(); el != nil; {
+		prev := el.Prev()
+		if s.expired(el.Value.(*storeEntry)) {
+			s.evict(el)
+		}
+		el = prev
+	}
+}
+
+func (s *entityStore) setPolicy(p CachePolicy) {
+	s.policy = p
+	if p.Disabled {
+		for el := s.order.Back(); el != nil; el = s.order.Back() {
+			s.evict(el)
+		}
+		return
+	}
+	s.trim()
+	s.purge()
+}
+
+func (s *entityStore) values() []interface{} {
+	result := make([]interface{}, 0, len(s.items))
+	for el := s.order.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*storeEntry); !s.expired(e) {
+			result = append(result, e.value)
+		}
+	}
+	return result
+}
+
+func (s *entityStore) snapshot() CacheStats {
+	stats := s.stats
+	stats.Size = len(s.items)
+	return stats
+}
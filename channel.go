@@ -1,60 +1,105 @@
 package dgofw
 
 import (
+	"encoding/json"
 	"fmt"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type DiscordChannel struct {
+	// mu is not embedded, since ``Lock`` and ``Unlock`` lock the channel itself.
+	mu     sync.RWMutex
 	c      *discordgo.Channel
 	client *DiscordClient
-	Guild  *DiscordGuild
+	guild  *DiscordGuild
 }
 
 func NewDiscordChannel(client *DiscordClient, c *discordgo.Channel) *DiscordChannel {
 	return &DiscordChannel{
 		c:      c,
 		client: client,
-		Guild:  client.Cache.GetGuild(c.GuildID),
 	}
 }
 
+func (c *DiscordChannel) raw() *discordgo.Channel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.c
+}
+
+func (c *DiscordChannel) set(raw *discordgo.Channel) {
+	c.mu.Lock()
+	c.c = raw
+	c.mu.Unlock()
+}
+
 func (c *DiscordChannel) ID() string {
-	return c.c.ID
+	return c.raw().ID
 }
 
 func (c *DiscordChannel) NSFW() bool {
-	return c.c.NSFW
+	return c.raw().NSFW
 }
 
 func (c *DiscordChannel) Name() string {
-	return c.c.Name
+	return c.raw().Name
 }
 
 func (c *DiscordChannel) Position() int {
-	return c.c.Position
+	return c.raw().Position
 }
 
 func (c *DiscordChannel) Topic() string {
-	return c.c.Topic
+	return c.raw().Topic
 }
 
 func (c *DiscordChannel) GuildID() string {
-	return c.c.GuildID
+	return c.raw().GuildID
+}
+
+// Guild returns the guild of the channel, or nil for private channels.
+// It is looked up on first use.
+func (c *DiscordChannel) Guild() *DiscordGuild {
+	c.mu.RLock()
+	guild := c.guild
+	c.mu.RUnlock()
+	if guild != nil {
+		return guild
+	}
+
+	id := c.GuildID()
+	if id == "" {
+		return nil
+	}
+
+	guild = c.client.Cache.GetGuild(id)
+	c.mu.Lock()
+	c.guild = guild
+	c.mu.Unlock()
+	return guild
+}
+
+// ParentID is the ID of the category the channel is in, if any.
+func (c *DiscordChannel) ParentID() string {
+	return c.raw().ParentID
 }
 
 func (c *DiscordChannel) Type() discordgo.ChannelType {
-	return c.c.Type
+	return c.raw().Type
 }
 
+// Send sends a message to the channel.
+//
+// Messages longer than ``MessageLimit`` are split, and the last part is returned.
 func (c *DiscordChannel) Send(msg string) *DiscordMessage {
-	m, err := c.client.ses.ChannelMessageSend(c.ID(), msg)
-	if err != nil {
-		return nil
+	if utf8.RuneCountInString(msg) > MessageLimit {
+		return last(c.SendSplit(msg, nil))
 	}
 
-	return NewDiscordMessage(c.client, m)
+	return c.client.Send(c.ID(), msg)
 }
 
 func (c *DiscordChannel) Messages(count int, before, after, around string) []*DiscordMessage {
@@ -69,20 +114,10 @@ func (c *DiscordChannel) Messages(count int, before, after, around string) []*Di
 	return result
 }
 
+// DeleteMessages deletes the last ``count`` messages, see ``Purge``.
 func (c *DiscordChannel) DeleteMessages(count int) {
-	msgs, err := c.client.ses.ChannelMessages(c.ID(), count, "", "", "")
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-
-	result := make([]string, len(msgs))
-	for i, msg := range msgs {
-		result[i] = msg.ID
-	}
-
-	if err = c.client.ses.ChannelMessagesBulkDelete(c.ID(), result); err != nil {
-		fmt.Println(err)
+	if _, err := c.Purge(PurgeOptions{Limit: count}); err != nil {
+		c.client.reportError(err)
 	}
 }
 
@@ -97,3 +132,177 @@ func (c *DiscordChannel) Message(id string) *DiscordMessage {
 func (c *DiscordChannel) PinMessage(id string) {
 	c.client.ses.ChannelMessagePin(c.ID(), id)
 }
+
+func (c *DiscordChannel) UnpinMessage(id string) error {
+	return c.client.ses.ChannelMessageUnpin(c.ID(), id)
+}
+
+// Pins returns the pinned messages of the channel.
+func (c *DiscordChannel) Pins() ([]*DiscordMessage, error) {
+	msgs, err := c.client.ses.ChannelMessagesPinned(c.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DiscordMessage, len(msgs))
+	for i, m := range msgs {
+		result[i] = NewDiscordMessage(c.client, m)
+	}
+	return result, nil
+}
+
+// ChannelTypeGuildNews is an announcement channel, kept from before discordgo knew about it.
+const ChannelTypeGuildNews = discordgo.ChannelTypeGuildNews
+
+// ChannelOptions are the settings of a channel to create or edit.
+// Empty names, types and parents and nil fields are left out, so ``Edit`` leaves them unchanged.
+// Use ``SetParent`` to move a channel out of its category.
+type ChannelOptions struct {
+	Name      string                `json:"name,omitempty"`
+	Type      discordgo.ChannelType `json:"type,omitempty"`
+	Topic     *string               `json:"topic,omitempty"`
+	NSFW      *bool                 `json:"nsfw,omitempty"`
+	Bitrate   *int                  `json:"bitrate,omitempty"`
+	UserLimit *int                  `json:"user_limit,omitempty"`
+	Slowmode  *int                  `json:"rate_limit_per_user,omitempty"`
+	Position  *int                  `json:"position,omitempty"`
+	ParentID  string                `json:"parent_id,omitempty"`
+
+	Overwrites []*Overwrite `json:"-"`
+	Reason     string       `json:"-"`
+}
+
+// channelParams adds the overwrites to the options in the form Discord expects.
+type channelParams struct {
+	*ChannelOptions
+	PermissionOverwrites []*discordgo.PermissionOverwrite `json:"permission_overwrites,omitempty"`
+}
+
+func (o *ChannelOptions) params() *channelParams {
+	result := &channelParams{ChannelOptions: o}
+	for _, ow := range o.Overwrites {
+		result.PermissionOverwrites = append(result.PermissionOverwrites, ow.raw())
+	}
+	return result
+}
+
+// patch sends ``data`` to the channel, and caches the changed channel.
+func (c *DiscordChannel) patch(data interface{}, reason string) error {
+	uri := discordgo.EndpointChannel(c.ID())
+	res, err := c.client.ses.RequestWithBucketID("PATCH", uri, data, uri, auditReason(reason)...)
+	if err != nil {
+		return err
+	}
+
+	ch := new(discordgo.Channel)
+	if err = json.Unmarshal(res, ch); err != nil {
+		return err
+	}
+	c.client.Cache.UpdateChannel(ch)
+	return nil
+}
+
+// Edit changes the settings of the channel.
+func (c *DiscordChannel) Edit(opts ChannelOptions) error {
+	return c.patch(opts.params(), opts.Reason)
+}
+
+func (c *DiscordChannel) SetName(name string) error {
+	return c.patch(map[string]interface{}{"name": name}, "")
+}
+
+// SetTopic changes the topic, an empty topic clears it.
+func (c *DiscordChannel) SetTopic(topic string) error {
+	return c.patch(map[string]interface{}{"topic": topic}, "")
+}
+
+// SetParent moves the channel into a category, or out of it if ``parent`` is empty.
+func (c *DiscordChannel) SetParent(parent string) error {
+	var id interface{}
+	if parent != "" {
+		id = parent
+	}
+	return c.patch(map[string]interface{}{"parent_id": id}, "")
+}
+
+// Move changes the position of the channel among the channels of its guild.
+func (c *DiscordChannel) Move(position int) error {
+	data := []map[string]interface{}{{"id": c.ID(), "position": position}}
+	uri := discordgo.EndpointGuildChannels(c.GuildID())
+	if _, err := c.client.ses.RequestWithBucketID("PATCH", uri, data, uri); err != nil {
+		return err
+	}
+
+	raw := *c.raw()
+	raw.Position = position
+	c.client.Cache.UpdateChannel(&raw)
+	return nil
+}
+
+// Delete deletes the channel, or closes it if it's a private channel.
+func (c *DiscordChannel) Delete(reason string) error {
+	uri := discordgo.EndpointChannel(c.ID())
+	if _, err := c.client.ses.RequestWithBucketID("DELETE", uri, nil, uri, auditReason(reason)...); err != nil {
+		return err
+	}
+
+	c.client.Cache.DeleteChannel(c.ID())
+	return nil
+}
+
+// Clone creates a copy of the channel with its settings and overwrites, but without messages.
+// An empty ``name`` keeps the name of the channel.
+func (c *DiscordChannel) Clone(name string) (*DiscordChannel, error) {
+	g := c.Guild()
+	if g == nil {
+		return nil, fmt.Errorf("channel %s is not in a guild", c.ID())
+	}
+
+	raw := c.raw()
+	if name == "" {
+		name = raw.Name
+	}
+	opts := ChannelOptions{
+		Name:       name,
+		Type:       raw.Type,
+		Topic:      &raw.Topic,
+		NSFW:       &raw.NSFW,
+		Slowmode:   &raw.RateLimitPerUser,
+		Position:   &raw.Position,
+		ParentID:   raw.ParentID,
+		Overwrites: c.Overwrites(),
+	}
+	if raw.Type == discordgo.ChannelTypeGuildVoice {
+		opts.Bitrate = &raw.Bitrate
+		opts.UserLimit = &raw.UserLimit
+	}
+	return g.CreateChannel(opts)
+}
+
+func (c *DiscordChannel) IsCategory() bool {
+	return c.Type() == discordgo.ChannelTypeGuildCategory
+}
+
+// Parent returns the category of the channel, or nil.
+func (c *DiscordChannel) Parent() *DiscordChannel {
+	if id := c.ParentID(); id != "" {
+		return c.client.Cache.GetChannel(id)
+	}
+	return nil
+}
+
+// Children returns the channels in the category, ordered by position.
+func (c *DiscordChannel) Children() []*DiscordChannel {
+	g := c.Guild()
+	if g == nil || !c.IsCategory() {
+		return nil
+	}
+
+	result := make([]*DiscordChannel, 0)
+	for _, ch := range g.Channels() {
+		if ch.ParentID() == c.ID() {
+			result = append(result, ch)
+		}
+	}
+	return result
+}
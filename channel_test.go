@@ -0,0 +1,111 @@
+package dgofw
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestChannelOptionsZeroValues(t *testing.T) {
+	topic, nsfw, slowmode, position := "", false, 0, 0
+	opts := ChannelOptions{
+		Topic:    &topic,
+		NSFW:     &nsfw,
+		Slowmode: &slowmode,
+		Position: &position,
+	}
+
+	data, err := json.Marshal(opts.params())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"topic":"","nsfw":false,"rate_limit_per_user":0,"position":0}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	if data, _ = json.Marshal(new(ChannelOptions).params()); string(data) != "{}" {
+		t.Errorf("got %s for no changes", data)
+	}
+}
+
+// channelTransport creates channels, and records the requests it gets.
+type channelTransport struct {
+	sync.Mutex
+	requests []string
+}
+
+func (t *channelTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+	t.Lock()
+	t.requests = append(t.requests, r.Method+" "+string(body))
+	t.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"id": "9", "guild_id": "1", "name": "clone", "parent_id": "10", "position": 3}`)),
+		Request:    r,
+	}, nil
+}
+
+func channelIDs(channels []*DiscordChannel) string {
+	ids := make([]string, len(channels))
+	for i, ch := range channels {
+		ids[i] = ch.ID()
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestGuildChannels(t *testing.T) {
+	c, _ := newMessageClient()
+	transport := new(channelTransport)
+	c.ses.Client = &http.Client{Transport: transport}
+
+	category := testChannel("10", "1")
+	category.Type = discordgo.ChannelTypeGuildCategory
+	c.Cache.UpdateChannel(category)
+	for _, ch := range []*discordgo.Channel{
+		{ID: "11", GuildID: "1", Name: "rules", ParentID: "10", Position: 2, RateLimitPerUser: 10},
+		{ID: "12", GuildID: "1", ParentID: "10", Position: 1},
+		{ID: "13", GuildID: "1", ParentID: "11", Type: discordgo.ChannelTypeGuildPublicThread},
+		{ID: "14", GuildID: "other", ParentID: "10"},
+	} {
+		c.Cache.UpdateChannel(ch)
+	}
+
+	g := c.Cache.GetGuild("1")
+	if got := channelIDs(g.Channels()); got != "10,2,12,11" {
+		t.Errorf("got channels %s, want 10,2,12,11", got)
+	}
+	cat := c.Cache.GetChannel("10")
+	if got := channelIDs(cat.Children()); got != "12,11" {
+		t.Errorf("got children %s, want 12,11", got)
+	}
+
+	clone, err := c.Cache.GetChannel("11").Clone("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.requests) != 1 || !strings.Contains(transport.requests[0], `"rate_limit_per_user":10`) {
+		t.Errorf("made requests %q, want the channel created with its slowmode at once", transport.requests)
+	}
+	if got := channelIDs(cat.Children()); got != "12,11,9" {
+		t.Errorf("got children %s after cloning, want 12,11,9", got)
+	}
+
+	if err = clone.Delete(""); err != nil {
+		t.Fatal(err)
+	}
+	if got := channelIDs(cat.Children()); got != "12,11" {
+		t.Errorf("got children %s after deleting, want 12,11", got)
+	}
+}
@@ -1,23 +1,18 @@
 package dgofw
 
 import (
-	"fmt"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-type (
-	DiscordCache struct {
-		sync.RWMutex
-		client   *DiscordClient
-		users    map[string]*DiscordUser
-		members  map[string]*DiscordMember
-		guilds   map[string]*DiscordGuild
-		channels map[string]*DiscordChannel
-	}
+// DefaultIntents are all unprivileged intents, plus the members for the member cache
+// and the message content for command handlers.
+const DefaultIntents = discordgo.IntentsAllWithoutPrivileged | discordgo.IntentGuildMembers | discordgo.IntentMessageContent
 
+type (
 	Interceptor struct {
 		ID   string
 		Chan chan *DiscordMessage
@@ -29,149 +24,53 @@ type (
 		ses              *discordgo.Session
 		handlers         []*MsgHandler
 		interceptors     []*Interceptor
+		bus              eventBus
+		stream           guildStream
 		VoiceConnections []*DiscordVoiceConnection
-	}
-)
-
-// GetGuild gets a guild from the cache
-func (c *DiscordCache) GetGuild(id string) *DiscordGuild {
-	if g, ok := c.guilds[id]; ok {
-		return g
-	}
-
-	if g, err := c.client.ses.State.Guild(id); err == nil {
-		return NewDiscordGuild(c.client, g)
-	}
-
-	g, err := c.client.ses.Guild(id)
-	if err != nil {
-		return nil
-	}
-
-	return NewDiscordGuild(c.client, g)
-}
-
-func (c *DiscordCache) UpdateGuild(g *discordgo.Guild) *DiscordGuild {
-	var result *DiscordGuild
-	if gc, ok := c.guilds[g.ID]; ok {
-		gc.g = g
-		result = gc
-	} else {
-		result = NewDiscordGuild(c.client, g)
-		c.Lock()
-		c.guilds[g.ID] = result
-		c.Unlock()
-	}
-	return result
-}
-
-func (c *DiscordCache) DeleteGuild(id string) {
-	c.Lock()
-	if _, ok := c.guilds[id]; ok {
-		delete(c.guilds, id)
-	}
-	c.Unlock()
-}
-
-// GetChannel gets a channel from the cache
-func (c *DiscordCache) GetChannel(id string) *DiscordChannel {
-	if ch, ok := c.channels[id]; ok {
-		return ch
-	}
-
-	if ch, err := c.client.ses.State.Channel(id); err == nil {
-		return NewDiscordChannel(c.client, ch)
-	}
-
-	ch, err := c.client.ses.Channel(id)
-	if err != nil {
-		return nil
-	}
-
-	return NewDiscordChannel(c.client, ch)
-}
-
-func (c *DiscordCache) UpdateChannel(ch *discordgo.Channel) *DiscordChannel {
-	var result *DiscordChannel
-	if cc, ok := c.channels[ch.ID]; ok {
-		cc.c = ch
-		result = cc
-	} else {
-		result = NewDiscordChannel(c.client, ch)
-		c.Lock()
-		c.channels[ch.ID] = result
-		c.Unlock()
-	}
-	return result
-}
-
-func (c *DiscordCache) DeleteChannel(id string) {
-	c.Lock()
-	if _, ok := c.channels[id]; ok {
-		delete(c.channels, id)
-	}
-	c.Unlock()
-}
-
-// GetMember gets a member from the cache
-func (c *DiscordCache) GetMember(guild, id string) *DiscordMember {
-	if m, ok := c.members[id]; ok {
-		return m
-	}
-
-	if m, err := c.client.ses.State.Member(guild, id); err == nil {
-		return NewDiscordMember(c.client, m)
-	}
-
-	m, err := c.client.ses.GuildMember(guild, id)
-	if err != nil {
-		return nil
-	}
 
-	return NewDiscordMember(c.client, m)
-}
+		// AllowedMentions is the default mention policy for sent messages.
+		AllowedMentions AllowedMentions
 
-func (c *DiscordCache) UpdateMember(m *discordgo.Member) *DiscordMember {
-	var result *DiscordMember
-	if cm, ok := c.members[m.User.ID]; ok {
-		cm.m = m
-		result = cm
-	} else {
-		result = NewDiscordMember(c.client, m)
-		c.Lock()
-		c.members[m.User.ID] = result
-		c.Unlock()
-	}
-	return result
-}
-
-func (c *DiscordCache) DeleteMember(id string) {
-	c.Lock()
-	if _, ok := c.members[id]; ok {
-		delete(c.members, id)
+		// MaxAttachmentSize caps attachment downloads, in bytes.
+		// Defaults to ``DefaultMaxAttachmentSize``.
+		MaxAttachmentSize int
 	}
-	c.Unlock()
-}
-
-func (c *DiscordClient) initCache() {
-	c.Cache = &DiscordCache{
-		client:   c,
-		users:    make(map[string]*DiscordUser),
-		members:  make(map[string]*DiscordMember),
-		guilds:   make(map[string]*DiscordGuild),
-		channels: make(map[string]*DiscordChannel),
-	}
-}
+)
 
 func (c *DiscordClient) initEvents() {
 	// Message Event Handlers
 	c.ses.AddHandler(c.handleMessageC)
 	c.ses.AddHandler(c.handleMessageE)
 
-	// Guild Event Handlers
-
-	// We ignore GUILD_CREATE
+	// Ready and Guild Event Handlers
+	c.ses.AddHandler(c.handleReady)
+	c.ses.AddHandler(c.handleGuildC)
 	c.ses.AddHandler(c.handleGuildD)
+	c.ses.AddHandler(c.handleGuildUpdate)
+
+	// Channel and Member Event Handlers
+	c.ses.AddHandler(c.handleChannelCreate)
+	c.ses.AddHandler(c.handleChannelUpdate)
+	c.ses.AddHandler(c.handleChannelDelete)
+	c.ses.AddHandler(c.handleMemberAdd)
+	c.ses.AddHandler(c.handleMemberUpdate)
+	c.ses.AddHandler(c.handleMemberRemove)
+
+	// Role Event Handlers
+	c.ses.AddHandler(c.handleRoleCreate)
+	c.ses.AddHandler(c.handleRoleUpdate)
+	c.ses.AddHandler(c.handleRoleDelete)
+
+	// Thread Event Handlers
+	c.ses.AddHandler(c.handleThreadCreate)
+	c.ses.AddHandler(c.handleThreadUpdate)
+	c.ses.AddHandler(c.handleThreadDelete)
+	c.ses.AddHandler(c.handleThreadListSync)
+	c.ses.AddHandler(c.handleThreadMembersUpdate)
+
+	// User Event Handlers
+	c.ses.AddHandler(c.handleUserUpdate)
+	c.ses.AddHandler(c.handlePresenceUpdate)
 }
 
 func (c *DiscordClient) intercept(timeout int, id string, closer chan struct{}, onLimit func()) (reader *Interceptor) {
@@ -236,7 +135,9 @@ func NewDiscordClient(token string) *DiscordClient {
 	if err != nil {
 		panic(err)
 	}
+	result.ses.Identify.Intents = DefaultIntents
 	result.interceptors = make([]*Interceptor, 0)
+	result.AllowedMentions = *DefaultAllowedMentions.copy()
 	result.initCache()
 	result.initEvents()
 	return result
@@ -246,18 +147,33 @@ func NewDiscordClient(token string) *DiscordClient {
 func (c *DiscordClient) Connect() {
 	err := c.ses.Open()
 	if err != nil {
-		fmt.Println(err)
+		c.reportError(err)
 	}
 }
 
 // Disconnect disconnects a client
+//
+// Backends that can be saved, like ``SnapshotBackend``, are saved so a restart can warm the cache.
 func (c *DiscordClient) Disconnect() {
 	c.ses.Close()
+
+	if b, ok := c.Cache.Backend().(interface{ Save() error }); ok {
+		if err := b.Save(); err != nil {
+			c.reportError(err)
+		}
+	}
+}
+
+// SetIntents sets the events the gateway sends, it has to be called before ``Connect``.
+//
+// Privileged intents have to be enabled for the bot in the developer portal.
+func (c *DiscordClient) SetIntents(intents discordgo.Intent) {
+	c.ses.Identify.Intents = intents
 }
 
 // SetStatus sets the ``Playing ...`` status for the bot.
 func (c *DiscordClient) SetStatus(status string) {
-	c.ses.UpdateStatus(0, status)
+	c.ses.UpdateGameStatus(0, status)
 }
 
 func (c *DiscordClient) Channel(id string) *DiscordChannel {
@@ -267,13 +183,20 @@ func (c *DiscordClient) Channel(id string) *DiscordChannel {
 	return nil
 }
 
+// Send sends a message to a channel.
+//
+// Messages longer than ``MessageLimit`` are split, and the last part is returned.
 func (c *DiscordClient) Send(channel, msg string) *DiscordMessage {
-	m, err := c.ses.ChannelMessageSend(channel, msg)
+	if utf8.RuneCountInString(msg) > MessageLimit {
+		return last(c.SendSplit(channel, msg, nil))
+	}
+
+	m, err := c.NewMessage(channel).Content(msg).Send()
 	if err != nil {
 		return nil
 	}
 
-	return NewDiscordMessage(c, m)
+	return m
 }
 
 func (c *DiscordClient) SendEmbed(channel string, embed *discordgo.MessageEmbed) (err error) {
@@ -1,6 +1,7 @@
 package dgofw
 
 import (
+	"log"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
@@ -8,9 +9,12 @@ import (
 
 type (
 	MsgHandler struct {
-		once    bool
-		pattern string
-		cb      func(*DiscordMessage)
+		once     bool
+		pattern  string
+		desc     string
+		flags    []*Flag
+		channels []string
+		cb       func(*DiscordMessage)
 	}
 
 	DiscordGuildBan struct {
@@ -35,7 +39,7 @@ func (c *DiscordClient) handleMessageC(s *discordgo.Session, m *discordgo.Messag
 	}
 
 	msg := NewDiscordMessage(c, m.Message)
-	vals := strings.Fields(m.Content)
+	toks := tokenizeArgs(m.Content)
 	if len(c.interceptors) > 0 {
 		for _, iter := range c.interceptors {
 			if iter.ID == msg.ChannelID() {
@@ -46,9 +50,25 @@ func (c *DiscordClient) handleMessageC(s *discordgo.Session, m *discordgo.Messag
 
 	for i, handler := range c.handlers {
 		keys := strings.Fields(handler.pattern)
-		if len(vals) > 0 || len(m.Content) >= len(handler.pattern) {
+		if len(toks) > 0 || len(m.Content) >= len(handler.pattern) {
 			if strings.ToLower(m.Content) == strings.ToLower(handler.pattern) ||
-				strings.HasPrefix(strings.ToLower(vals[0]), strings.ToLower(keys[0])) {
+				strings.HasPrefix(strings.ToLower(toks[0].value), strings.ToLower(keys[0])) {
+
+				if !handler.matchChannel(msg) {
+					continue
+				}
+
+				keys, files, ok := matchAttachments(keys, msg.Attachments())
+				if !ok {
+					continue
+				}
+
+				vals, flags := handler.parseFlags(toks)
+				msg.Lock()
+				msg.args = vals
+				msg.flags = flags
+				msg.files = files
+				msg.Unlock()
 
 				if len(keys) > 0 || len(vals) > 0 {
 					msg.Pairs(keys, vals)
@@ -65,20 +85,74 @@ func (c *DiscordClient) handleMessageC(s *discordgo.Session, m *discordgo.Messag
 
 func (c *DiscordClient) handleMessageE(s *discordgo.Session, m *discordgo.MessageUpdate) {
 	if m.Author != nil {
-		c.handleMessageC(s, (*discordgo.MessageCreate)(m))
+		c.handleMessageC(s, &discordgo.MessageCreate{Message: m.Message})
 	}
 }
 
 // OnMessage handles a ``MESSAGE_*`` event.
 // Does not handle ``MESSAGE_DELETE``
-func (c *DiscordClient) OnMessage(pattern string, once bool, cb func(*DiscordMessage)) {
-	c.handlers = append(c.handlers, &MsgHandler{
+//
+// Arguments in the pattern are matched against quote aware arguments, see ``SplitArgs``.
+// Keys like ``{name:image}``, ``{name:text}`` or ``{name:attachment}`` require an uploaded file,
+// available through ``DiscordMessage.Attachment``.
+// The returned handler can be used to declare flags.
+func (c *DiscordClient) OnMessage(pattern string, once bool, cb func(*DiscordMessage)) *MsgHandler {
+	handler := &MsgHandler{
 		cb:      cb,
 		once:    once,
 		pattern: pattern,
+	}
+	c.handlers = append(c.handlers, handler)
+	return handler
+}
+
+// In limits the handler to messages sent in ``channels``, or in threads of them.
+func (h *MsgHandler) In(channels ...string) *MsgHandler {
+	h.channels = append(h.channels, channels...)
+	return h
+}
+
+func (h *MsgHandler) matchChannel(msg *DiscordMessage) bool {
+	if len(h.channels) == 0 {
+		return true
+	}
+
+	for _, id := range h.channels {
+		if id == msg.ChannelID() {
+			return true
+		}
+	}
+
+	// Only look the channel up when it's needed, this might hit the API.
+	ch := msg.Channel()
+	if ch == nil || !ch.IsThread() {
+		return false
+	}
+	for _, id := range h.channels {
+		if id == ch.ParentID() {
+			return true
+		}
+	}
+	return false
+}
+
+const eventError = "ERROR"
+
+// OnError handles errors of calls that can't return them, e.g. ``Reply``, or those
+// of event handlers like the invite tracker's. Without a handler errors are logged.
+func (c *DiscordClient) OnError(once bool, cb func(error)) {
+	c.bus.add(eventError, once, func(v interface{}) {
+		cb(v.(error))
 	})
 }
 
+// reportError passes an error that can't be returned on to ``OnError``.
+func (c *DiscordClient) reportError(err error) {
+	if !c.bus.emit(eventError, err) {
+		log.Println(err)
+	}
+}
+
 // OnMessageDeleted handles a ``MESSAGE_DELETE`` event
 //
 // ``MESSAGE_DELETE`` is a special case, since only 2 fields are present.
@@ -107,101 +181,150 @@ func (c *DiscordClient) OnReady(once bool, cb func(*discordgo.Ready)) {
 	}
 }
 
-func (c *DiscordClient) OnGuildUpdate(once bool, cb func(*DiscordGuild)) {
-	handleCb := func(_ *discordgo.Session, edit *discordgo.GuildUpdate) {
-		res := c.Cache.UpdateGuild(edit.Guild)
-		cb(res)
+const (
+	eventGuildUpdate   = "GUILD_UPDATE"
+	eventChannelCreate = "CHANNEL_CREATE"
+	eventChannelUpdate = "CHANNEL_UPDATE"
+	eventChannelDelete = "CHANNEL_DELETE"
+	eventMemberAdd     = "GUILD_MEMBER_ADD"
+	eventMemberUpdate  = "GUILD_MEMBER_UPDATE"
+	eventMemberRemove  = "GUILD_MEMBER_REMOVE"
+)
+
+// handleGuildUpdate caches the changed guild. ``GUILD_UPDATE`` doesn't carry
+// the members, channels and so on of the guild, so the cached ones are kept.
+func (c *DiscordClient) handleGuildUpdate(_ *discordgo.Session, edit *discordgo.GuildUpdate) {
+	raw := *edit.Guild
+	if cached, ok := c.Cache.cachedGuild(raw.ID); ok {
+		old := cached.raw()
+		if raw.Roles == nil {
+			raw.Roles = old.Roles
+		}
+		if raw.Emojis == nil {
+			raw.Emojis = old.Emojis
+		}
+		if raw.Members == nil {
+			raw.Members = old.Members
+		}
+		if raw.Presences == nil {
+			raw.Presences = old.Presences
+		}
+		if raw.Channels == nil {
+			raw.Channels = old.Channels
+		}
+		if raw.Threads == nil {
+			raw.Threads = old.Threads
+		}
+		if raw.VoiceStates == nil {
+			raw.VoiceStates = old.VoiceStates
+		}
+		if raw.MemberCount == 0 {
+			raw.MemberCount = old.MemberCount
+		}
 	}
+	c.bus.emit(eventGuildUpdate, c.Cache.UpdateGuild(&raw))
+}
 
-	if once {
-		c.ses.AddHandlerOnce(handleCb)
-	} else {
-		c.ses.AddHandler(handleCb)
+func (c *DiscordClient) OnGuildUpdate(once bool, cb func(*DiscordGuild)) {
+	c.bus.add(eventGuildUpdate, once, func(v interface{}) {
+		cb(v.(*DiscordGuild))
+	})
+}
+
+func (c *DiscordClient) handleUserUpdate(s *discordgo.Session, u *discordgo.UserUpdate) {
+	c.Cache.UpdateUser(u.User)
+}
+
+func (c *DiscordClient) handlePresenceUpdate(s *discordgo.Session, p *discordgo.PresenceUpdate) {
+	if p.User != nil {
+		c.Cache.UpdateUser(p.User)
 	}
 }
 
 func (c *DiscordClient) handleGuildD(s *discordgo.Session, g *discordgo.GuildDelete) {
+	// An unavailable guild is an outage, the bot is still in it.
+	if g.Unavailable {
+		c.stream.Lock()
+		if c.stream.unavailable == nil {
+			c.stream.unavailable = make(map[string]struct{})
+		}
+		c.stream.unavailable[g.ID] = struct{}{}
+		c.stream.Unlock()
+		return
+	}
 	c.Cache.DeleteGuild(g.ID)
 }
 
-func (c *DiscordClient) OnChannelCreate(once bool, cb func(*DiscordChannel)) {
-	hndlerCb := func(_ *discordgo.Session, cc *discordgo.ChannelCreate) {
-		ch := c.Cache.UpdateChannel(cc.Channel)
-		cb(ch)
-	}
+func (c *DiscordClient) handleChannelCreate(_ *discordgo.Session, cc *discordgo.ChannelCreate) {
+	c.bus.emit(eventChannelCreate, c.Cache.UpdateChannel(cc.Channel))
+}
 
-	if once {
-		c.ses.AddHandlerOnce(hndlerCb)
-	} else {
-		c.ses.AddHandler(hndlerCb)
-	}
+func (c *DiscordClient) handleChannelUpdate(_ *discordgo.Session, cu *discordgo.ChannelUpdate) {
+	c.bus.emit(eventChannelUpdate, c.Cache.UpdateChannel(cu.Channel))
 }
 
-func (c *DiscordClient) OnChannelUpdate(once bool, cb func(*DiscordChannel)) {
-	handlerCb := func(_ *discordgo.Session, cu *discordgo.ChannelUpdate) {
-		ch := c.Cache.UpdateChannel(cu.Channel)
-		cb(ch)
+func (c *DiscordClient) handleChannelDelete(_ *discordgo.Session, cd *discordgo.ChannelDelete) {
+	ch, ok := c.Cache.cachedChannel(cd.ID)
+	if !ok {
+		ch = NewDiscordChannel(c, cd.Channel)
 	}
+	c.Cache.DeleteChannel(cd.ID)
+	c.bus.emit(eventChannelDelete, ch)
+}
 
-	if once {
-		c.ses.AddHandlerOnce(handlerCb)
-	} else {
-		c.ses.AddHandler(handlerCb)
-	}
+func (c *DiscordClient) handleMemberAdd(_ *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	c.bus.emit(eventMemberAdd, c.Cache.UpdateMember(m.Member))
 }
 
-func (c *DiscordClient) OnChannelDelete(once bool, cb func(*DiscordChannel)) {
-	handlerCb := func(_ *discordgo.Session, cd *discordgo.ChannelDelete) {
-		ch := c.Cache.GetChannel(cd.ID)
-		c.Cache.DeleteChannel(cd.ID)
-		cb(ch)
-	}
+func (c *DiscordClient) handleMemberUpdate(_ *discordgo.Session, m *discordgo.GuildMemberUpdate) {
+	c.bus.emit(eventMemberUpdate, c.Cache.UpdateMember(m.Member))
+}
 
-	if once {
-		c.ses.AddHandlerOnce(handlerCb)
-	} else {
-		c.ses.AddHandler(handlerCb)
+func (c *DiscordClient) handleMemberRemove(_ *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	// The member already left, so don't look them up over REST.
+	mem, ok := c.Cache.cachedMember(m.GuildID, m.User.ID)
+	if !ok {
+		mem = NewDiscordMember(c, m.Member)
 	}
+	c.Cache.DeleteMember(m.GuildID, m.User.ID)
+	c.bus.emit(eventMemberRemove, mem)
 }
 
-func (c *DiscordClient) OnMemberAdd(once bool, cb func(*DiscordMember)) {
-	handlerCb := func(s *discordgo.Session, m *discordgo.GuildMemberAdd) {
-		mem := c.Cache.UpdateMember(m.Member)
-		cb(mem)
-	}
+func (c *DiscordClient) OnChannelCreate(once bool, cb func(*DiscordChannel)) {
+	c.bus.add(eventChannelCreate, once, func(v interface{}) {
+		cb(v.(*DiscordChannel))
+	})
+}
 
-	if once {
-		c.ses.AddHandlerOnce(handlerCb)
-	} else {
-		c.ses.AddHandler(handlerCb)
-	}
+func (c *DiscordClient) OnChannelUpdate(once bool, cb func(*DiscordChannel)) {
+	c.bus.add(eventChannelUpdate, once, func(v interface{}) {
+		cb(v.(*DiscordChannel))
+	})
 }
 
-func (c *DiscordClient) OnMemberRemove(once bool, cb func(*DiscordMember)) {
-	handlerCb := func(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
-		mem := c.Cache.GetMember(m.GuildID, m.User.ID)
-		c.Cache.DeleteMember(m.User.ID)
-		cb(mem)
-	}
+// OnChannelDelete handles a ``CHANNEL_DELETE`` event. The channel is only complete if it was cached.
+func (c *DiscordClient) OnChannelDelete(once bool, cb func(*DiscordChannel)) {
+	c.bus.add(eventChannelDelete, once, func(v interface{}) {
+		cb(v.(*DiscordChannel))
+	})
+}
 
-	if once {
-		c.ses.AddHandlerOnce(handlerCb)
-	} else {
-		c.ses.AddHandler(handlerCb)
-	}
+func (c *DiscordClient) OnMemberAdd(once bool, cb func(*DiscordMember)) {
+	c.bus.add(eventMemberAdd, once, func(v interface{}) {
+		cb(v.(*DiscordMember))
+	})
 }
 
-func (c *DiscordClient) OnMemberUpdate(once bool, cb func(*DiscordMember)) {
-	handlerCb := func(s *discordgo.Session, m *discordgo.GuildMemberUpdate) {
-		mem := c.Cache.UpdateMember(m.Member)
-		cb(mem)
-	}
+func (c *DiscordClient) OnMemberRemove(once bool, cb func(*DiscordMember)) {
+	c.bus.add(eventMemberRemove, once, func(v interface{}) {
+		cb(v.(*DiscordMember))
+	})
+}
 
-	if once {
-		c.ses.AddHandlerOnce(handlerCb)
-	} else {
-		c.ses.AddHandler(handlerCb)
-	}
+func (c *DiscordClient) OnMemberUpdate(once bool, cb func(*DiscordMember)) {
+	c.bus.add(eventMemberUpdate, once, func(v interface{}) {
+		cb(v.(*DiscordMember))
+	})
 }
 
 // WithMemberChunk handles a ``GuildMembersChunk`` event.
@@ -219,14 +342,9 @@ func (c *DiscordClient) WithMemberChunk(once bool, cb func(*discordgo.GuildMembe
 func (c *DiscordClient) WithGuildBanAdd(once bool, cb func(*DiscordGuildBan)) {
 	guildBanAddCb := func(s *discordgo.Session, ban *discordgo.GuildBanAdd) {
 		result := &DiscordGuildBan{
-			User: NewDiscordUser(c, ban.User),
-		}
-		if cg := c.Cache.GetGuild(ban.GuildID); cg != nil {
-			result.Guild = cg
-		} else {
-			g, _ := s.Guild(ban.GuildID)
-			result.Guild = NewDiscordGuild(c, g)
+			User: c.Cache.UpdateUser(ban.User),
 		}
+		result.Guild = c.Cache.GetGuild(ban.GuildID)
 		cb(result)
 	}
 
@@ -240,14 +358,9 @@ func (c *DiscordClient) WithGuildBanAdd(once bool, cb func(*DiscordGuildBan)) {
 func (c *DiscordClient) WithGuildBanRemove(once bool, cb func(*DiscordGuildBan)) {
 	guildBanRemoveCb := func(s *discordgo.Session, ban *discordgo.GuildBanRemove) {
 		result := &DiscordGuildBan{
-			User: NewDiscordUser(c, ban.User),
-		}
-		if cg := c.Cache.GetGuild(ban.GuildID); cg != nil {
-			result.Guild = cg
-		} else {
-			g, _ := s.Guild(ban.GuildID)
-			result.Guild = NewDiscordGuild(c, g)
+			User: c.Cache.UpdateUser(ban.User),
 		}
+		result.Guild = c.Cache.GetGuild(ban.GuildID)
 		cb(result)
 	}
 
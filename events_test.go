@@ -0,0 +1,88 @@
+package dgofw
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestChannelEvents(t *testing.T) {
+	c, requests := newMessageClient()
+	events := make(chan string, 3)
+	c.OnChannelCreate(false, func(ch *DiscordChannel) { events <- "create " + ch.Name() })
+	c.OnChannelUpdate(false, func(ch *DiscordChannel) { events <- "update " + ch.Name() })
+	c.OnChannelDelete(false, func(ch *DiscordChannel) { events <- "delete " + ch.Name() })
+
+	ch := testChannel("6", "1")
+	c.handleChannelCreate(c.ses, &discordgo.ChannelCreate{Channel: ch})
+	waitEvent(t, events, "create channel 6")
+	if _, ok := c.Cache.cachedChannel("6"); !ok {
+		t.Fatal("created channel not cached")
+	}
+
+	edit := testChannel("6", "1")
+	edit.Name = "renamed"
+	c.handleChannelUpdate(c.ses, &discordgo.ChannelUpdate{Channel: edit})
+	waitEvent(t, events, "update renamed")
+
+	// Only the ID matters, the cached channel is passed on.
+	c.handleChannelDelete(c.ses, &discordgo.ChannelDelete{Channel: &discordgo.Channel{ID: "6"}})
+	waitEvent(t, events, "delete renamed")
+	if _, ok := c.Cache.cachedChannel("6"); ok {
+		t.Error("deleted channel still cached")
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d requests, want 0", n)
+	}
+}
+
+func TestMemberEvents(t *testing.T) {
+	c, requests := newMessageClient()
+	events := make(chan string, 3)
+	c.OnMemberAdd(false, func(m *DiscordMember) { events <- "add " + m.User.ID() })
+	c.OnMemberUpdate(false, func(m *DiscordMember) { events <- "update " + m.Nickname() })
+	c.OnMemberRemove(false, func(m *DiscordMember) { events <- "remove " + m.Nickname() })
+
+	c.handleMemberAdd(c.ses, &discordgo.GuildMemberAdd{Member: testMember("1", "7")})
+	waitEvent(t, events, "add 7")
+	if c.Cache.MemberCount("1") != 4 {
+		t.Errorf("got %d members, want 4", c.Cache.MemberCount("1"))
+	}
+
+	m := testMember("1", "7")
+	m.Nick = "nick"
+	c.handleMemberUpdate(c.ses, &discordgo.GuildMemberUpdate{Member: m})
+	waitEvent(t, events, "update nick")
+
+	c.handleMemberRemove(c.ses, &discordgo.GuildMemberRemove{Member: testMember("1", "7")})
+	waitEvent(t, events, "remove nick")
+	if _, ok := c.Cache.cachedMember("1", "7"); ok {
+		t.Error("removed member still cached")
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d requests, want 0", n)
+	}
+}
+
+func TestGuildUpdateKeepsCachedLists(t *testing.T) {
+	c, _ := newTestClient()
+	g := testGuild("1")
+	g.Roles = []*discordgo.Role{{ID: "1", Name: "@everyone"}}
+	g.Channels = []*discordgo.Channel{testChannel("2", "1")}
+	g.MemberCount = 10
+	c.Cache.UpdateGuild(g)
+
+	events := make(chan string, 1)
+	c.OnGuildUpdate(false, func(g *DiscordGuild) { events <- g.Name() })
+
+	edit := testGuild("1")
+	edit.Name = "renamed"
+	c.handleGuildUpdate(c.ses, &discordgo.GuildUpdate{Guild: edit})
+	waitEvent(t, events, "renamed")
+
+	cached, _ := c.Cache.cachedGuild("1")
+	raw := cached.raw()
+	if len(raw.Roles) != 1 || len(raw.Channels) != 1 || raw.MemberCount != 10 {
+		t.Errorf("got %d roles, %d channels and %d members, want the cached ones", len(raw.Roles), len(raw.Channels), raw.MemberCount)
+	}
+}
@@ -0,0 +1,54 @@
+package dgofw
+
+import (
+	"errors"
+	"sync"
+)
+
+// errFlightPanic is what callers waiting on a call get if it panicked.
+var errFlightPanic = errors.New("coalesced call panicked")
+
+type (
+	// flightGroup coalesces concurrent calls for the same key into one.
+	flightGroup struct {
+		sync.Mutex
+		calls map[string]*flightCall
+	}
+
+	flightCall struct {
+		wg  sync.WaitGroup
+		val interface{}
+		err error
+	}
+)
+
+// do calls fn, unless a call for key is already in flight,
+// in which case it waits for that call and shares its result.
+func (g *flightGroup) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+
+	call := new(flightCall)
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.Unlock()
+
+	// Release the waiters even if fn panics, so they don't block forever.
+	defer func() {
+		g.Lock()
+		delete(g.calls, key)
+		g.Unlock()
+		call.wg.Done()
+	}()
+
+	call.err = errFlightPanic
+	call.val, call.err = fn()
+	return call.val, call.err
+}
@@ -0,0 +1,143 @@
+// Package format contains helpers for escaping, formatting and parsing Discord message markup.
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// zeroWidthSpace is inserted to break up mentions without changing how the text looks.
+const zeroWidthSpace = "\u200b"
+
+var (
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"*", `\*`,
+		"_", `\_`,
+		"~", `\~`,
+		"`", "\\`",
+		"|", `\|`,
+		">", `\>`,
+	)
+
+	markdownStripper = strings.NewReplacer(
+		"*", "",
+		"_", "",
+		"~", "",
+		"`", "",
+		"|", "",
+	)
+
+	massMentionRe = regexp.MustCompile(`@(everyone|here)`)
+	inviteRe      = regexp.MustCompile(`(?i)(discord(?:app)?\.(?:gg|com/invite|io|me|li))/`)
+	mentionRe     = regexp.MustCompile(`<(@[!&]?|#)(\d+)>`)
+)
+
+// EscapeMarkdown escapes all Markdown formatting characters in s.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// StripMarkdown removes all Markdown formatting characters from s.
+func StripMarkdown(s string) string {
+	return markdownStripper.Replace(s)
+}
+
+// EscapeMassMentions neutralizes @everyone and @here.
+func EscapeMassMentions(s string) string {
+	return massMentionRe.ReplaceAllString(s, "@"+zeroWidthSpace+"$1")
+}
+
+// EscapeMentions neutralizes user, role and channel mentions, as well as @everyone and @here.
+func EscapeMentions(s string) string {
+	s = mentionRe.ReplaceAllString(s, "<$1"+zeroWidthSpace+"$2>")
+	return EscapeMassMentions(s)
+}
+
+// EscapeInvites breaks up invite links so Discord doesn't embed them.
+func EscapeInvites(s string) string {
+	return inviteRe.ReplaceAllString(s, "$1"+zeroWidthSpace+"/")
+}
+
+// Escape escapes Markdown, mentions and invite links in s.
+// It is meant for echoing untrusted input back into a channel.
+func Escape(s string) string {
+	return EscapeInvites(EscapeMarkdown(EscapeMentions(s)))
+}
+
+// User formats a user mention.
+func User(id string) string {
+	return "<@" + id + ">"
+}
+
+// Nickname formats a user mention that shows the nickname.
+func Nickname(id string) string {
+	return "<@!" + id + ">"
+}
+
+// Channel formats a channel mention.
+func Channel(id string) string {
+	return "<#" + id + ">"
+}
+
+// Role formats a role mention.
+func Role(id string) string {
+	return "<@&" + id + ">"
+}
+
+// Emoji formats a custom emoji.
+func Emoji(name, id string, animated bool) string {
+	if animated {
+		return "<a:" + name + ":" + id + ">"
+	}
+	return "<:" + name + ":" + id + ">"
+}
+
+// TimestampStyle is the way Discord renders a timestamp.
+type TimestampStyle string
+
+const (
+	TimestampShortTime     TimestampStyle = "t"
+	TimestampLongTime      TimestampStyle = "T"
+	TimestampShortDate     TimestampStyle = "d"
+	TimestampLongDate      TimestampStyle = "D"
+	TimestampShortDateTime TimestampStyle = "f"
+	TimestampLongDateTime  TimestampStyle = "F"
+	TimestampRelative      TimestampStyle = "R"
+)
+
+// Timestamp formats a timestamp rendered in the reader's timezone.
+// An empty style uses Discord's default.
+func Timestamp(t time.Time, style TimestampStyle) string {
+	if style == "" {
+		return fmt.Sprintf("<t:%d>", t.Unix())
+	}
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style)
+}
+
+// Bold wraps s in bold markup.
+func Bold(s string) string {
+	return "**" + s + "**"
+}
+
+// Italic wraps s in italic markup.
+func Italic(s string) string {
+	return "*" + s + "*"
+}
+
+// Code wraps s in inline code markup.
+func Code(s string) string {
+	return "`" + s + "`"
+}
+
+// CodeBlock wraps s in a code block with an optional language.
+func CodeBlock(lang, s string) string {
+	return "```" + lang + "\n" + s + "\n```"
+}
+
+// Spoiler wraps s in spoiler markup.
+func Spoiler(s string) string {
+	return "||" + s + "||"
+}
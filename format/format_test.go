@@ -0,0 +1,64 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscapers(t *testing.T) {
+	const zws = zeroWidthSpace
+	tests := []struct {
+		name string
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{"markdown", EscapeMarkdown, `*a_b* ~c~ |d| > e \ ` + "`f`", `\*a\_b\* \~c\~ \|d\| \> e \\ ` + "\\`f\\`"},
+		{"strip markdown", StripMarkdown, "**bold** __u__ ~~s~~ `c` ||x||", "bold u s c x"},
+		{"mass mentions", EscapeMassMentions, "@everyone and @here", "@" + zws + "everyone and @" + zws + "here"},
+		{"user mention", EscapeMentions, "<@123>", "<@" + zws + "123>"},
+		{"nickname mention", EscapeMentions, "<@!123>", "<@!" + zws + "123>"},
+		{"role mention", EscapeMentions, "<@&123>", "<@&" + zws + "123>"},
+		{"channel mention", EscapeMentions, "<#123>", "<#" + zws + "123>"},
+		{"mentions and everyone", EscapeMentions, "<@1> @here", "<@" + zws + "1> @" + zws + "here"},
+		{"no mentions", EscapeMentions, "mail@example.com <notamention>", "mail@example.com <notamention>"},
+		{"invite", EscapeInvites, "join discord.gg/abc", "join discord.gg" + zws + "/abc"},
+		{"invite url", EscapeInvites, "https://discord.com/invite/abc", "https://discord.com/invite" + zws + "/abc"},
+		{"old invite url", EscapeInvites, "https://DiscordApp.com/invite/abc", "https://DiscordApp.com/invite" + zws + "/abc"},
+		{"no invite", EscapeInvites, "discord.com/channels/1", "discord.com/channels/1"},
+		{"everything", Escape, "<@1> **hi** discord.gg/x", "<@" + zws + "1\\> \\*\\*hi\\*\\* discord.gg" + zws + "/x"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	ts := time.Unix(1600000000, 0)
+	tests := []struct {
+		got, want string
+	}{
+		{User("1"), "<@1>"},
+		{Nickname("1"), "<@!1>"},
+		{Channel("1"), "<#1>"},
+		{Role("1"), "<@&1>"},
+		{Emoji("wave", "1", false), "<:wave:1>"},
+		{Emoji("wave", "1", true), "<a:wave:1>"},
+		{Timestamp(ts, ""), "<t:1600000000>"},
+		{Timestamp(ts, TimestampRelative), "<t:1600000000:R>"},
+		{Bold("a"), "**a**"},
+		{Italic("a"), "*a*"},
+		{Code("a"), "`a`"},
+		{CodeBlock("go", "a"), "```go\na\n```"},
+		{Spoiler("a"), "||a||"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package format
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// TokenType is the kind of markup a Token represents.
+type TokenType int
+
+const (
+	TokenUser TokenType = iota
+	TokenChannel
+	TokenRole
+	TokenEmoji
+	TokenTimestamp
+)
+
+// Token is a piece of Discord markup found in message content.
+type Token struct {
+	Type TokenType
+
+	// Raw is the token as it appears in the content.
+	Raw string
+
+	// Start and End are the byte offsets of Raw in the content.
+	Start, End int
+
+	// ID is the snowflake of the user, channel, role or emoji.
+	ID string
+
+	// Name is the emoji name.
+	Name string
+
+	// Animated reports whether an emoji is animated.
+	Animated bool
+
+	// Time and Style are set for timestamps.
+	Time  time.Time
+	Style TimestampStyle
+}
+
+var tokenRe = regexp.MustCompile(`<(?:@!?(\d+)|#(\d+)|@&(\d+)|(a?):(\w{2,32}):(\d+)|t:(-?\d+)(?::([tTdDfFR]))?)>`)
+
+// Tokenize returns all mention, emoji and timestamp tokens in content.
+func Tokenize(content string) []*Token {
+	matches := tokenRe.FindAllStringSubmatchIndex(content, -1)
+	result := make([]*Token, 0, len(matches))
+	for _, m := range matches {
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return content[m[2*i]:m[2*i+1]]
+		}
+
+		tok := &Token{
+			Raw:   content[m[0]:m[1]],
+			Start: m[0],
+			End:   m[1],
+		}
+
+		switch {
+		case group(1) != "":
+			tok.Type = TokenUser
+			tok.ID = group(1)
+		case group(2) != "":
+			tok.Type = TokenChannel
+			tok.ID = group(2)
+		case group(3) != "":
+			tok.Type = TokenRole
+			tok.ID = group(3)
+		case group(6) != "":
+			tok.Type = TokenEmoji
+			tok.Animated = group(4) == "a"
+			tok.Name = group(5)
+			tok.ID = group(6)
+		default:
+			unix, err := strconv.ParseInt(group(7), 10, 64)
+			if err != nil {
+				continue
+			}
+			tok.Type = TokenTimestamp
+			tok.Time = time.Unix(unix, 0).UTC()
+			tok.Style = TimestampStyle(group(8))
+		}
+		result = append(result, tok)
+	}
+	return result
+}
+
+// Users returns the IDs of all mentioned users in content.
+func Users(content string) []string {
+	return ids(content, TokenUser)
+}
+
+// Channels returns the IDs of all mentioned channels in content.
+func Channels(content string) []string {
+	return ids(content, TokenChannel)
+}
+
+// Roles returns the IDs of all mentioned roles in content.
+func Roles(content string) []string {
+	return ids(content, TokenRole)
+}
+
+func ids(content string, t TokenType) []string {
+	result := make([]string, 0)
+	for _, tok := range Tokenize(content) {
+		if tok.Type == t {
+			result = append(result, tok.ID)
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Token
+	}{
+		{"<@1>", Token{Type: TokenUser, ID: "1"}},
+		{"<@!1>", Token{Type: TokenUser, ID: "1"}},
+		{"<#2>", Token{Type: TokenChannel, ID: "2"}},
+		{"<@&3>", Token{Type: TokenRole, ID: "3"}},
+		{"<:wave:4>", Token{Type: TokenEmoji, ID: "4", Name: "wave"}},
+		{"<a:wave:4>", Token{Type: TokenEmoji, ID: "4", Name: "wave", Animated: true}},
+		{"<t:1600000000>", Token{Type: TokenTimestamp, Time: time.Unix(1600000000, 0).UTC()}},
+		{"<t:1600000000:R>", Token{Type: TokenTimestamp, Time: time.Unix(1600000000, 0).UTC(), Style: TimestampRelative}},
+		{"<t:-60:d>", Token{Type: TokenTimestamp, Time: time.Unix(-60, 0).UTC(), Style: TimestampShortDate}},
+	}
+
+	for _, tt := range tests {
+		content := "before " + tt.in + " after"
+		toks := Tokenize(content)
+		if len(toks) != 1 {
+			t.Errorf("%s: got %d tokens, want 1", tt.in, len(toks))
+			continue
+		}
+
+		want := tt.want
+		want.Raw = tt.in
+		want.Start = len("before ")
+		want.End = want.Start + len(tt.in)
+		if !reflect.DeepEqual(*toks[0], want) {
+			t.Errorf("%s: got %+v, want %+v", tt.in, *toks[0], want)
+		}
+	}
+}
+
+func TestTokenizeIgnores(t *testing.T) {
+	for _, in := range []string{"<@abc>", "<:a:1>", "<t:1:x>", "<#>", "@everyone", "<@1"} {
+		if toks := Tokenize(in); len(toks) != 0 {
+			t.Errorf("%s: got %d tokens, want none", in, len(toks))
+		}
+	}
+}
+
+func TestTokenIDs(t *testing.T) {
+	content := "<@1> <@!2> <#3> <@&4> <@&5> <:e:6>"
+	tests := []struct {
+		fn   func(string) []string
+		want string
+	}{
+		{Users, "1,2"},
+		{Channels, "3"},
+		{Roles, "4,5"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(tt.fn(content), ","); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
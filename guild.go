@@ -1,17 +1,22 @@
 package dgofw
 
 import (
+	"encoding/json"
+	"errors"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type DiscordGuild struct {
+	sync.RWMutex
 	client *DiscordClient
 	g      *discordgo.Guild
 	Colors map[string]int
-	Owner  *DiscordMember
+	owner  *DiscordMember
 }
 
 func NewDiscordGuild(client *DiscordClient, g *discordgo.Guild) *DiscordGuild {
@@ -19,41 +24,43 @@ func NewDiscordGuild(client *DiscordClient, g *discordgo.Guild) *DiscordGuild {
 		client: client,
 		g:      g,
 		Colors: make(map[string]int),
-		Owner:  nil,
-	}
-	if owner := client.Cache.GetMember(g.ID, g.OwnerID); owner != nil {
-		result.Owner = owner
-	} else {
-		m, err := client.ses.GuildMember(g.ID, g.OwnerID)
-		if err == nil && m != nil {
-			result.Owner = NewDiscordMember(client, m)
-		}
 	}
 	return result
 }
 
+func (g *DiscordGuild) raw() *discordgo.Guild {
+	g.RLock()
+	defer g.RUnlock()
+	return g.g
+}
+
+func (g *DiscordGuild) set(raw *discordgo.Guild) {
+	g.Lock()
+	if g.owner != nil && g.g.OwnerID != raw.OwnerID {
+		g.owner = nil
+	}
+	g.g = raw
+	g.Unlock()
+}
+
 func (g *DiscordGuild) ID() string {
-	return g.g.ID
+	return g.raw().ID
 }
 
 func (g *DiscordGuild) VoiceStates() []*discordgo.VoiceState {
-	return g.g.VoiceStates
+	return g.raw().VoiceStates
 }
 
 func (g *DiscordGuild) Emojis() []*discordgo.Emoji {
-	return g.g.Emojis
+	return g.raw().Emojis
 }
 
 func (g *DiscordGuild) Icon() string {
-	return discordgo.EndpointGuildIcon(g.ID(), g.g.Icon)
+	return discordgo.EndpointGuildIcon(g.ID(), g.raw().Icon)
 }
 
 func (g *DiscordGuild) Name() string {
-	return g.g.Name
-}
-
-func (g *DiscordGuild) Roles() []*discordgo.Role {
-	return g.g.Roles
+	return g.raw().Name
 }
 
 func (g *DiscordGuild) Client() *DiscordClient {
@@ -61,15 +68,31 @@ func (g *DiscordGuild) Client() *DiscordClient {
 }
 
 func (g *DiscordGuild) MemberCount() int {
-	return g.g.MemberCount
+	return g.raw().MemberCount
 }
 
 func (g *DiscordGuild) OwnerID() string {
-	return g.g.OwnerID
+	return g.raw().OwnerID
+}
+
+// Owner returns the owner of the guild. It is looked up on first use.
+func (g *DiscordGuild) Owner() *DiscordMember {
+	g.RLock()
+	owner := g.owner
+	g.RUnlock()
+	if owner != nil {
+		return owner
+	}
+
+	owner = g.client.Cache.GetMember(g.ID(), g.OwnerID())
+	g.Lock()
+	g.owner = owner
+	g.Unlock()
+	return owner
 }
 
 func (g *DiscordGuild) Region() string {
-	return g.g.Region
+	return g.raw().Region
 }
 
 func (g *DiscordGuild) CreatedAt() string {
@@ -79,11 +102,11 @@ func (g *DiscordGuild) CreatedAt() string {
 }
 
 func (g *DiscordGuild) Edit(params discordgo.GuildParams) {
-	g.client.ses.GuildEdit(g.ID(), params)
+	g.client.ses.GuildEdit(g.ID(), &params)
 }
 
 func (g *DiscordGuild) Members() []*DiscordMember {
-	iter := g.g.Members
+	iter := g.raw().Members
 	result := make([]*DiscordMember, len(iter))
 	for i, m := range iter {
 		if cm := g.client.Cache.GetMember(g.ID(), m.User.ID); cm != nil {
@@ -99,7 +122,7 @@ func (g *DiscordGuild) Member(id string) *DiscordMember {
 	if mem := g.client.Cache.GetMember(g.ID(), id); mem != nil {
 		return mem
 	}
-	for _, mem := range g.g.Members {
+	for _, mem := range g.raw().Members {
 		if mem.User.ID == id {
 			return NewDiscordMember(g.client, mem)
 		}
@@ -107,15 +130,41 @@ func (g *DiscordGuild) Member(id string) *DiscordMember {
 	return nil
 }
 
+// Channels returns the cached channels of the guild, without threads, ordered by position.
+//
+// Channels are cached as they are created and deleted, so these are up to date,
+// unless a ``CachePolicy`` limits how many channels are kept.
 func (g *DiscordGuild) Channels() []*DiscordChannel {
-	iter := g.g.Channels
-	result := make([]*DiscordChannel, len(iter))
-	for i, c := range iter {
-		if cc := g.client.Cache.GetChannel(c.ID); cc != nil {
-			result[i] = cc
-		} else {
-			result[i] = NewDiscordChannel(g.client, c)
+	result := make([]*DiscordChannel, 0)
+	for _, ch := range g.client.Cache.Channels(g.ID()) {
+		if !ch.IsThread() {
+			result = append(result, ch)
 		}
 	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Position() != result[j].Position() {
+			return result[i].Position() < result[j].Position()
+		}
+		return result[i].ID() < result[j].ID()
+	})
 	return result
 }
+
+// CreateChannel creates a channel in the guild. Without a type a text channel is created.
+func (g *DiscordGuild) CreateChannel(opts ChannelOptions) (*DiscordChannel, error) {
+	if opts.Name == "" {
+		return nil, errors.New("channel name is required")
+	}
+
+	uri := discordgo.EndpointGuildChannels(g.ID())
+	res, err := g.client.ses.RequestWithBucketID("POST", uri, opts.params(), uri, auditReason(opts.Reason)...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := new(discordgo.Channel)
+	if err = json.Unmarshal(res, ch); err != nil {
+		return nil, err
+	}
+	return g.client.Cache.UpdateChannel(ch), nil
+}
@@ -0,0 +1,115 @@
+package dgofw
+
+import "errors"
+
+var (
+	// ErrHierarchy is returned when the target of a moderation action
+	// is the guild owner, or has a role as high as, or higher than, the moderator.
+	ErrHierarchy = errors.New("target is not below the moderator in the role hierarchy")
+
+	// ErrMissingPermission is returned when the moderator lacks the permission for an action.
+	ErrMissingPermission = errors.New("missing permission")
+)
+
+// Me returns the bot user.
+func (c *DiscordClient) Me() *DiscordUser {
+	return c.Cache.GetUser(c.ses.State.User.ID)
+}
+
+// BotMember returns the bot as a member of ``guild``.
+func (c *DiscordClient) BotMember(guild string) *DiscordMember {
+	return c.Cache.GetMember(guild, c.ses.State.User.ID)
+}
+
+// position is the position of the highest role of the member.
+func (m *DiscordMember) position() int {
+	if r := m.TopRole(); r != nil {
+		return r.Position()
+	}
+	return 0
+}
+
+// IsOwner reports whether the member owns the guild.
+func (m *DiscordMember) IsOwner() bool {
+	g := m.Guild()
+	return g != nil && g.OwnerID() == m.User.ID()
+}
+
+// CanModerate reports whether ``actor`` outranks ``target``: the owner outranks everyone,
+// everyone else needs a higher top role. Nobody can moderate themselves.
+func CanModerate(actor, target *DiscordMember) bool {
+	if actor == nil || target == nil || actor.GuildID() != target.GuildID() {
+		return false
+	}
+
+	if actor.User.ID() == target.User.ID() || target.IsOwner() {
+		return false
+	}
+	if actor.IsOwner() {
+		return true
+	}
+	return actor.position() > target.position()
+}
+
+// BotCanModerate reports whether the bot outranks ``target``.
+func BotCanModerate(target *DiscordMember) bool {
+	if target == nil {
+		return false
+	}
+	return CanModerate(target.client.BotMember(target.GuildID()), target)
+}
+
+// CheckModerate checks that ``actor`` has ``perm`` and outranks ``target``,
+// e.g. to check the invoker of a command before acting on their behalf.
+func CheckModerate(actor, target *DiscordMember, perm Permissions) error {
+	if actor == nil || !actor.Permissions().Has(perm) {
+		return ErrMissingPermission
+	}
+	if !CanModerate(actor, target) {
+		return ErrHierarchy
+	}
+	return nil
+}
+
+// checkBot checks that the bot has ``perm`` in ``guild``.
+// If the bot member can't be found the check is left to Discord.
+func (c *DiscordClient) checkBot(guild string, perm Permissions) error {
+	bot := c.BotMember(guild)
+	if bot != nil && !bot.Permissions().Has(perm) {
+		return ErrMissingPermission
+	}
+	return nil
+}
+
+// checkBotModerate is like ``CheckModerate`` for the bot.
+func (c *DiscordClient) checkBotModerate(target *DiscordMember, perm Permissions) error {
+	if target == nil {
+		return ErrHierarchy
+	}
+	bot := c.BotMember(target.GuildID())
+	if bot == nil {
+		return nil
+	}
+	return CheckModerate(bot, target, perm)
+}
+
+// checkBotRole checks that the bot can assign ``role``, which has to be below its top role.
+func (c *DiscordClient) checkBotRole(guild, role string) error {
+	bot := c.BotMember(guild)
+	if bot == nil {
+		return nil
+	}
+
+	if !bot.Permissions().Has(PermissionManageRoles) {
+		return ErrMissingPermission
+	}
+
+	g := bot.Guild()
+	if g == nil || bot.IsOwner() {
+		return nil
+	}
+	if r := g.Role(role); r != nil && r.Position() >= bot.position() {
+		return ErrHierarchy
+	}
+	return nil
+}
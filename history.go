@@ -0,0 +1,284 @@
+package dgofw
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BulkDeleteMaxAge is the age after which messages can't be bulk deleted anymore.
+const BulkDeleteMaxAge = 14 * 24 * time.Hour
+
+// historyPageSize is the most messages Discord returns per request.
+const historyPageSize = 100
+
+// HistoryOptions select a range of messages in a channel.
+type HistoryOptions struct {
+	// Before and After are message IDs bounding the range, both exclusive.
+	Before string
+	After  string
+
+	// Limit is the most messages returned, 0 is unlimited.
+	Limit int
+
+	// Forward walks from the oldest message to the newest instead of the other way around.
+	Forward bool
+}
+
+// History iterates over the messages of a channel, fetching pages as they are needed.
+//
+//	it := channel.History(dgofw.HistoryOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Message().Content())
+//	}
+//	if it.Err() != nil { ... }
+type History struct {
+	ch     *DiscordChannel
+	opts   HistoryOptions
+	cursor string
+	buf    []*DiscordMessage
+	cur    *DiscordMessage
+	count  int
+	done   bool
+	err    error
+}
+
+// History returns an iterator over the messages in the channel.
+func (c *DiscordChannel) History(opts HistoryOptions) *History {
+	result := &History{
+		ch:     c,
+		opts:   opts,
+		cursor: opts.Before,
+	}
+	if opts.Forward {
+		result.cursor = opts.After
+		if result.cursor == "" {
+			result.cursor = "0"
+		}
+	}
+	return result
+}
+
+// Next advances to the next message, it returns false when there are no more or an error occurred.
+func (h *History) Next() bool {
+	if h.opts.Limit > 0 && h.count >= h.opts.Limit {
+		return false
+	}
+
+	if len(h.buf) == 0 {
+		if h.done || !h.fetch() {
+			return false
+		}
+	}
+
+	h.cur, h.buf = h.buf[0], h.buf[1:]
+	h.count++
+	return true
+}
+
+// Message returns the current message.
+func (h *History) Message() *DiscordMessage {
+	return h.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (h *History) Err() error {
+	return h.err
+}
+
+// fetch loads the next page into the buffer.
+func (h *History) fetch() bool {
+	size := historyPageSize
+	if h.opts.Limit > 0 && h.opts.Limit-h.count < size {
+		size = h.opts.Limit - h.count
+	}
+
+	var before, after string
+	if h.opts.Forward {
+		after = h.cursor
+	} else {
+		before = h.cursor
+	}
+
+	msgs, err := h.ch.client.ses.ChannelMessages(h.ch.ID(), size, before, after, "")
+	if err != nil {
+		h.err, h.done = err, true
+		return false
+	}
+	if len(msgs) < size {
+		h.done = true
+	}
+
+	// Pages always come newest first.
+	for i := range msgs {
+		m := msgs[i]
+		if h.opts.Forward {
+			m = msgs[len(msgs)-1-i]
+		}
+
+		if h.opts.Forward && h.opts.Before != "" && !snowflakeLess(m.ID, h.opts.Before) ||
+			!h.opts.Forward && h.opts.After != "" && !snowflakeLess(h.opts.After, m.ID) {
+			h.done = true
+			break
+		}
+		h.buf = append(h.buf, NewDiscordMessage(h.ch.client, m))
+	}
+
+	if len(msgs) > 0 {
+		if h.opts.Forward {
+			h.cursor = msgs[0].ID
+		} else {
+			h.cursor = msgs[len(msgs)-1].ID
+		}
+	}
+	return len(h.buf) > 0
+}
+
+// snowflakeLess reports whether ``a`` was created before ``b``.
+func snowflakeLess(a, b string) bool {
+	x, _ := strconv.ParseUint(a, 10, 64)
+	y, _ := strconv.ParseUint(b, 10, 64)
+	return x < y
+}
+
+// snowflakeTime returns the time an ID was created at.
+func snowflakeTime(id string) time.Time {
+	n, _ := strconv.ParseUint(id, 10, 64)
+	ms := int64(n>>22) + 1420070400000
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+// PurgeOptions select the messages to delete. All filters that are set have to match.
+type PurgeOptions struct {
+	// Limit is the amount of messages to look at, defaults to 100.
+	Limit int
+
+	// Before and After are message IDs bounding the range, both exclusive.
+	Before string
+	After  string
+
+	// User only deletes messages by this user.
+	User string
+
+	// Bots only deletes messages by bots.
+	Bots bool
+
+	// Contains only deletes messages containing the text, ignoring case.
+	Contains string
+
+	// Attachments only deletes messages with files attached.
+	Attachments bool
+
+	// Pattern only deletes messages matching the expression.
+	Pattern *regexp.Regexp
+
+	// Filter is called for every message that matches the rest of the filters.
+	Filter func(*DiscordMessage) bool
+}
+
+// PurgeResult counts what a purge did.
+type PurgeResult struct {
+	Scanned int
+	Matched int
+	Bulk    int
+	Single  int
+	Failed  int
+}
+
+// Deleted is the amount of messages deleted.
+func (r *PurgeResult) Deleted() int {
+	return r.Bulk + r.Single
+}
+
+func (o *PurgeOptions) match(m *DiscordMessage) bool {
+	switch {
+	case o.User != "" && m.Author.ID() != o.User:
+		return false
+	case o.Bots && !m.Author.Bot():
+		return false
+	case o.Contains != "" && !strings.Contains(strings.ToLower(m.Content()), strings.ToLower(o.Contains)):
+		return false
+	case o.Attachments && len(m.m.Attachments) == 0:
+		return false
+	case o.Pattern != nil && !o.Pattern.MatchString(m.Content()):
+		return false
+	case o.Filter != nil && !o.Filter(m):
+		return false
+	}
+	return true
+}
+
+// Purge deletes the messages matching ``opts``. Messages younger than ``BulkDeleteMaxAge``
+// are bulk deleted in batches of 100, older ones one by one.
+//
+// Deleting continues past errors, the last one is returned with the counts so far.
+func (c *DiscordChannel) Purge(opts PurgeOptions) (*PurgeResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = historyPageSize
+	}
+
+	result := new(PurgeResult)
+	var lastErr error
+
+	// Leave a minute of slack, so messages don't age out while the purge runs.
+	cutoff := time.Now().Add(-BulkDeleteMaxAge + time.Minute)
+	bulk := make([]string, 0, historyPageSize)
+	old := make([]string, 0)
+
+	flush := func() {
+		switch len(bulk) {
+		case 0:
+			return
+		case 1:
+			// Bulk deletes need at least two messages.
+			old = append(old, bulk[0])
+		default:
+			if err := c.client.ses.ChannelMessagesBulkDelete(c.ID(), bulk); err != nil {
+				lastErr = err
+				result.Failed += len(bulk)
+			} else {
+				result.Bulk += len(bulk)
+			}
+		}
+		bulk = bulk[:0]
+	}
+
+	it := c.History(HistoryOptions{
+		Before: opts.Before,
+		After:  opts.After,
+		Limit:  opts.Limit,
+	})
+	for it.Next() {
+		m := it.Message()
+		result.Scanned++
+		if !opts.match(m) {
+			continue
+		}
+		result.Matched++
+
+		if snowflakeTime(m.ID()).After(cutoff) {
+			bulk = append(bulk, m.ID())
+			if len(bulk) == historyPageSize {
+				flush()
+			}
+		} else {
+			old = append(old, m.ID())
+		}
+	}
+	flush()
+
+	if err := it.Err(); err != nil {
+		lastErr = err
+	}
+
+	for _, id := range old {
+		if err := c.client.ses.ChannelMessageDelete(c.ID(), id); err != nil {
+			lastErr = err
+			result.Failed++
+		} else {
+			result.Single++
+		}
+	}
+	return result, lastErr
+}
@@ -0,0 +1,350 @@
+package dgofw
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const eventMemberJoin = "MEMBER_JOIN"
+
+type DiscordInvite struct {
+	client *DiscordClient
+	i      *discordgo.Invite
+}
+
+// InviteOptions are the settings of a new invite.
+type InviteOptions struct {
+	// MaxAge is how many seconds the invite is valid for, 0 is forever.
+	MaxAge int `json:"max_age"`
+
+	// MaxUses is how often the invite can be used, 0 is unlimited.
+	MaxUses int `json:"max_uses"`
+
+	// Temporary invites kick members again once they go offline, unless they got a role.
+	Temporary bool `json:"temporary"`
+
+	// Unique creates a new invite even if a similar one exists.
+	Unique bool   `json:"unique"`
+	Reason string `json:"-"`
+}
+
+func NewDiscordInvite(client *DiscordClient, i *discordgo.Invite) *DiscordInvite {
+	return &DiscordInvite{
+		client: client,
+		i:      i,
+	}
+}
+
+func (i *DiscordInvite) Code() string {
+	return i.i.Code
+}
+
+func (i *DiscordInvite) URL() string {
+	return "https://discord.gg/" + i.Code()
+}
+
+func (i *DiscordInvite) Uses() int {
+	return i.i.Uses
+}
+
+// MaxUses is how often the invite can be used, 0 is unlimited.
+func (i *DiscordInvite) MaxUses() int {
+	return i.i.MaxUses
+}
+
+// MaxAge is how many seconds the invite is valid for, 0 is forever.
+func (i *DiscordInvite) MaxAge() int {
+	return i.i.MaxAge
+}
+
+func (i *DiscordInvite) Temporary() bool {
+	return i.i.Temporary
+}
+
+func (i *DiscordInvite) GuildID() string {
+	if i.i.Guild != nil {
+		return i.i.Guild.ID
+	}
+	return ""
+}
+
+func (i *DiscordInvite) ChannelID() string {
+	if i.i.Channel != nil {
+		return i.i.Channel.ID
+	}
+	return ""
+}
+
+func (i *DiscordInvite) Guild() *DiscordGuild {
+	return i.client.Cache.GetGuild(i.GuildID())
+}
+
+func (i *DiscordInvite) Channel() *DiscordChannel {
+	return i.client.Cache.GetChannel(i.ChannelID())
+}
+
+// Inviter returns the user who created the invite, or nil.
+func (i *DiscordInvite) Inviter() *DiscordUser {
+	if i.i.Inviter == nil {
+		return nil
+	}
+	return i.client.Cache.UpdateUser(i.i.Inviter)
+}
+
+func (i *DiscordInvite) Client() *DiscordClient {
+	return i.client
+}
+
+func (i *DiscordInvite) Delete(reason string) error {
+	uri := discordgo.EndpointInvite(i.Code())
+	_, err := i.client.ses.RequestWithBucketID("DELETE", uri, nil, discordgo.EndpointInvite(""), auditReason(reason)...)
+	return err
+}
+
+func wrapInvites(client *DiscordClient, invites []*discordgo.Invite) []*DiscordInvite {
+	result := make([]*DiscordInvite, len(invites))
+	for i, inv := range invites {
+		result[i] = NewDiscordInvite(client, inv)
+	}
+	return result
+}
+
+// Invites returns the invites of the guild.
+func (g *DiscordGuild) Invites() ([]*DiscordInvite, error) {
+	invites, err := g.client.ses.GuildInvites(g.ID())
+	if err != nil {
+		return nil, err
+	}
+	return wrapInvites(g.client, invites), nil
+}
+
+// DeleteInvite deletes an invite by its code.
+func (g *DiscordGuild) DeleteInvite(code, reason string) error {
+	return NewDiscordInvite(g.client, &discordgo.Invite{Code: code}).Delete(reason)
+}
+
+// Invites returns the invites to the channel.
+func (c *DiscordChannel) Invites() ([]*DiscordInvite, error) {
+	invites, err := c.client.ses.ChannelInvites(c.ID())
+	if err != nil {
+		return nil, err
+	}
+	return wrapInvites(c.client, invites), nil
+}
+
+// CreateInvite creates an invite to the channel.
+func (c *DiscordChannel) CreateInvite(opts InviteOptions) (*DiscordInvite, error) {
+	uri := discordgo.EndpointChannelInvites(c.ID())
+	res, err := c.client.ses.RequestWithBucketID("POST", uri, opts, uri, auditReason(opts.Reason)...)
+	if err != nil {
+		return nil, err
+	}
+
+	i := new(discordgo.Invite)
+	if err = json.Unmarshal(res, i); err != nil {
+		return nil, err
+	}
+	return NewDiscordInvite(c.client, i), nil
+}
+
+// MemberJoin is a new member, and the invite they joined through if it is known.
+type MemberJoin struct {
+	Member *DiscordMember
+	Invite *DiscordInvite
+}
+
+// InviteTracker finds out which invite new members used, by comparing
+// the uses of all invites of the guild before and after they joined.
+//
+// The bot needs the Manage Server permission to list invites.
+type InviteTracker struct {
+	sync.Mutex
+	client *DiscordClient
+	uses   map[string]map[string]*discordgo.Invite
+
+	// guilds serializes the comparisons per guild, without holding up other guilds while fetching.
+	guilds map[string]*sync.Mutex
+
+	// event is the bus key of the tracker's joins, so trackers don't get each other's.
+	event string
+}
+
+// NewInviteTracker starts tracking invites in all guilds.
+func (c *DiscordClient) NewInviteTracker() *InviteTracker {
+	t := &InviteTracker{
+		client: c,
+		uses:   make(map[string]map[string]*discordgo.Invite),
+		guilds: make(map[string]*sync.Mutex),
+	}
+	t.event = fmt.Sprintf("%s:%p", eventMemberJoin, t)
+
+	c.OnGuildAvailable(false, t.snapshot)
+	c.OnGuildJoin(false, t.snapshot)
+	c.ses.AddHandler(t.handleMemberAdd)
+	c.ses.AddHandler(t.handleGuildDelete)
+	c.ses.AddHandler(t.handleInviteCreate)
+	c.ses.AddHandler(t.handleInviteDelete)
+	return t
+}
+
+// fetch lists the invites of a guild by code.
+func (t *InviteTracker) fetch(guild string) (map[string]*discordgo.Invite, error) {
+	invites, err := t.client.ses.GuildInvites(guild)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*discordgo.Invite, len(invites))
+	for _, i := range invites {
+		result[i.Code] = i
+	}
+	return result, nil
+}
+
+// guild returns the lock for the comparisons in a guild.
+func (t *InviteTracker) guild(id string) *sync.Mutex {
+	t.Lock()
+	defer t.Unlock()
+	mu, ok := t.guilds[id]
+	if !ok {
+		mu = new(sync.Mutex)
+		t.guilds[id] = mu
+	}
+	return mu
+}
+
+func (t *InviteTracker) snapshot(g *DiscordGuild) {
+	mu := t.guild(g.ID())
+	mu.Lock()
+	defer mu.Unlock()
+
+	invites, err := t.fetch(g.ID())
+	if err != nil {
+		t.client.reportError(err)
+		return
+	}
+
+	t.Lock()
+	t.uses[g.ID()] = invites
+	t.Unlock()
+}
+
+func (t *InviteTracker) handleGuildDelete(_ *discordgo.Session, g *discordgo.GuildDelete) {
+	if g.Unavailable {
+		return
+	}
+
+	// The guild's lock is kept, a join that is being compared may still hold it.
+	t.Lock()
+	delete(t.uses, g.ID)
+	t.Unlock()
+}
+
+// handleInviteCreate adds a new invite, so its first use can be attributed.
+func (t *InviteTracker) handleInviteCreate(_ *discordgo.Session, i *discordgo.InviteCreate) {
+	mu := t.guild(i.GuildID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	t.Lock()
+	defer t.Unlock()
+	if invites, ok := t.uses[i.GuildID]; ok {
+		invite := *i.Invite
+		invite.Guild = &discordgo.Guild{ID: i.GuildID}
+		invite.Channel = &discordgo.Channel{ID: i.ChannelID}
+		invites[invite.Code] = &invite
+	}
+}
+
+// handleInviteDelete drops a deleted invite. An invite one use away from its limit
+// is kept, it may have been used up by a member who is about to be compared.
+func (t *InviteTracker) handleInviteDelete(_ *discordgo.Session, i *discordgo.InviteDelete) {
+	mu := t.guild(i.GuildID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	t.Lock()
+	defer t.Unlock()
+	if old, ok := t.uses[i.GuildID][i.Code]; ok && (old.MaxUses == 0 || old.Uses+1 < old.MaxUses) {
+		delete(t.uses[i.GuildID], i.Code)
+	}
+}
+
+// attribute finds the invite whose uses went up. An invite that reached its
+// use limit is deleted by Discord, so it is the one that went missing.
+func attribute(before, after map[string]*discordgo.Invite) *discordgo.Invite {
+	var found *discordgo.Invite
+	for code, i := range after {
+		old, ok := before[code]
+		if ok && i.Uses > old.Uses || !ok && i.Uses > 0 {
+			if found != nil {
+				// More than one candidate, e.g. two members joined at once.
+				return nil
+			}
+			found = i
+		}
+	}
+	if found != nil {
+		return found
+	}
+
+	for code, old := range before {
+		if _, ok := after[code]; !ok && old.MaxUses > 0 && old.Uses+1 == old.MaxUses {
+			if found != nil {
+				return nil
+			}
+			found = old
+		}
+	}
+	return found
+}
+
+func (t *InviteTracker) handleMemberAdd(_ *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	join := &MemberJoin{
+		Member: t.client.Cache.UpdateMember(m.Member),
+	}
+
+	// Hold the guild's lock while fetching, so joins in the same guild are compared one after another.
+	mu := t.guild(m.GuildID)
+	mu.Lock()
+	after, err := t.fetch(m.GuildID)
+	if err != nil {
+		mu.Unlock()
+		t.client.reportError(err)
+		t.client.bus.emit(t.event, join)
+		return
+	}
+
+	t.Lock()
+	if before, ok := t.uses[m.GuildID]; ok {
+		if i := attribute(before, after); i != nil {
+			join.Invite = NewDiscordInvite(t.client, i)
+		}
+	}
+	t.uses[m.GuildID] = after
+	t.Unlock()
+	mu.Unlock()
+
+	t.client.bus.emit(t.event, join)
+}
+
+// Uses returns how often each invite of a guild has been used, as last seen.
+func (t *InviteTracker) Uses(guild string) map[string]int {
+	t.Lock()
+	defer t.Unlock()
+	result := make(map[string]int, len(t.uses[guild]))
+	for code, i := range t.uses[guild] {
+		result[code] = i.Uses
+	}
+	return result
+}
+
+// OnMemberJoin handles a ``GUILD_MEMBER_ADD`` event, with the invite the member used if it could be found.
+func (t *InviteTracker) OnMemberJoin(once bool, cb func(*MemberJoin)) {
+	t.client.bus.add(t.event, once, func(v interface{}) {
+		cb(v.(*MemberJoin))
+	})
+}
@@ -0,0 +1,139 @@
+package dgofw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestAttribute(t *testing.T) {
+	invites := func(list ...*discordgo.Invite) map[string]*discordgo.Invite {
+		result := make(map[string]*discordgo.Invite)
+		for _, i := range list {
+			result[i.Code] = i
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		before, after map[string]*discordgo.Invite
+		want          string
+	}{
+		{
+			name:   "uses went up",
+			before: invites(&discordgo.Invite{Code: "a", Uses: 1}, &discordgo.Invite{Code: "b"}),
+			after:  invites(&discordgo.Invite{Code: "a", Uses: 2}, &discordgo.Invite{Code: "b"}),
+			want:   "a",
+		},
+		{
+			name:   "created since the last comparison",
+			before: invites(&discordgo.Invite{Code: "a"}),
+			after:  invites(&discordgo.Invite{Code: "a"}, &discordgo.Invite{Code: "b", Uses: 1}),
+			want:   "b",
+		},
+		{
+			name:   "used up and deleted",
+			before: invites(&discordgo.Invite{Code: "a", Uses: 4, MaxUses: 5}, &discordgo.Invite{Code: "b"}),
+			after:  invites(&discordgo.Invite{Code: "b"}),
+			want:   "a",
+		},
+		{
+			name:   "deleted by hand",
+			before: invites(&discordgo.Invite{Code: "a", Uses: 1, MaxUses: 5}),
+			after:  invites(),
+		},
+		{
+			name:   "two joins at once",
+			before: invites(&discordgo.Invite{Code: "a"}, &discordgo.Invite{Code: "b"}),
+			after:  invites(&discordgo.Invite{Code: "a", Uses: 1}, &discordgo.Invite{Code: "b", Uses: 1}),
+		},
+	}
+
+	for _, tt := range tests {
+		var got string
+		if i := attribute(tt.before, tt.after); i != nil {
+			got = i.Code
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInviteEvents(t *testing.T) {
+	c, _ := newTestClient()
+	tr := c.NewInviteTracker()
+	tr.uses["1"] = map[string]*discordgo.Invite{
+		"full": {Code: "full", Uses: 4, MaxUses: 5},
+		"old":  {Code: "old", Uses: 1},
+	}
+
+	tr.handleInviteCreate(c.ses, &discordgo.InviteCreate{Invite: &discordgo.Invite{Code: "new"}, GuildID: "1", ChannelID: "2"})
+	tr.handleInviteCreate(c.ses, &discordgo.InviteCreate{Invite: &discordgo.Invite{Code: "other"}, GuildID: "9", ChannelID: "8"})
+	tr.handleInviteDelete(c.ses, &discordgo.InviteDelete{Code: "old", GuildID: "1"})
+	tr.handleInviteDelete(c.ses, &discordgo.InviteDelete{Code: "full", GuildID: "1"})
+
+	uses := tr.Uses("1")
+	if _, ok := uses["new"]; !ok {
+		t.Error("created invite not tracked")
+	}
+	if _, ok := uses["old"]; ok {
+		t.Error("deleted invite still tracked")
+	}
+	if _, ok := uses["full"]; !ok {
+		t.Error("invite that may have been used up was dropped")
+	}
+	if len(tr.Uses("9")) != 0 {
+		t.Error("invite tracked in a guild that wasn't snapshotted")
+	}
+	if i := tr.uses["1"]["new"]; NewDiscordInvite(c, i).GuildID() != "1" || NewDiscordInvite(c, i).ChannelID() != "2" {
+		t.Error("created invite lost its guild or channel")
+	}
+}
+
+func TestInviteTrackerGuildDelete(t *testing.T) {
+	c, _ := newTestClient()
+	tr := c.NewInviteTracker()
+	tr.uses["1"] = map[string]*discordgo.Invite{}
+	mu := tr.guild("1")
+
+	tr.handleGuildDelete(c.ses, &discordgo.GuildDelete{Guild: &discordgo.Guild{ID: "1"}})
+	if _, ok := tr.uses["1"]; ok {
+		t.Error("uses of a left guild kept")
+	}
+	if tr.guild("1") != mu {
+		t.Error("the guild's lock was replaced while it may be held")
+	}
+}
+
+func TestInviteTrackersSeparate(t *testing.T) {
+	c, _ := newMessageClient()
+	first, second := c.NewInviteTracker(), c.NewInviteTracker()
+	events := make(chan string, 3)
+	first.OnMemberJoin(false, func(j *MemberJoin) { events <- "first " + j.Member.User.ID() })
+	second.OnMemberJoin(false, func(j *MemberJoin) { events <- "second " + j.Member.User.ID() })
+	c.OnError(false, func(error) { events <- "error" })
+
+	// Listing the invites fails, the join is passed on without one.
+	first.handleMemberAdd(c.ses, &discordgo.GuildMemberAdd{Member: testMember("1", "7")})
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-events:
+			got[e] = true
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+	if !got["first 7"] || !got["error"] {
+		t.Errorf("got %v, want the join and the error reported", got)
+	}
+
+	select {
+	case e := <-events:
+		t.Errorf("got %s, want nothing from the other tracker", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
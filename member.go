@@ -1,53 +1,55 @@
 package dgofw
 
 import (
-	"fmt"
-	"time"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type DiscordMember struct {
+	sync.RWMutex
 	client *DiscordClient
 	m      *discordgo.Member
 	User   *DiscordUser
-	Roles  []*discordgo.Role
 }
 
 func NewDiscordMember(client *DiscordClient, m *discordgo.Member) *DiscordMember {
 	result := &DiscordMember{
 		client: client,
 		m:      m,
-		Roles:  make([]*discordgo.Role, 0),
 	}
 
-	result.User = NewDiscordUser(client, m.User)
+	result.User = client.Cache.UpdateUser(m.User)
 	return result
 }
 
+func (m *DiscordMember) raw() *discordgo.Member {
+	m.RLock()
+	defer m.RUnlock()
+	return m.m
+}
+
+func (m *DiscordMember) set(raw *discordgo.Member) {
+	m.Lock()
+	m.m = raw
+	m.Unlock()
+}
+
 func (m *DiscordMember) Guild() *DiscordGuild {
-	if res := m.client.Cache.GetGuild(m.m.GuildID); res != nil {
-		return res
-	}
-	g, err := m.client.Cache.client.ses.Guild(m.m.GuildID)
-	if err != nil {
-		return nil
-	}
-	return NewDiscordGuild(m.client, g)
+	return m.client.Cache.GetGuild(m.GuildID())
 }
 
 func (m *DiscordMember) GuildID() string {
-	return m.m.GuildID
+	return m.raw().GuildID
 }
 
 func (m *DiscordMember) Nickname() string {
-	return m.m.Nick
+	return m.raw().Nick
 }
 
 func (m *DiscordMember) JoinedAt() string {
-	t, err := time.Parse(time.RFC3339Nano, m.m.JoinedAt)
-	if err != nil {
-		fmt.Println(err)
+	t := m.raw().JoinedAt
+	if t.IsZero() {
 		return "<nil>"
 	}
 	return t.UTC().Format("2006-01-02 15:04:05")
@@ -55,16 +57,29 @@ func (m *DiscordMember) JoinedAt() string {
 
 func (m *DiscordMember) Color() int {
 	g := m.Guild()
-	if g != nil {
-		if c, ok := g.Colors[m.User.ID()]; ok {
-			return c
-		}
+	if g == nil {
+		return 0
 	}
-	c := m.client.ses.State.UserColor(m.User.ID(), g.Channels()[0].ID())
+
+	g.RLock()
+	c, ok := g.Colors[m.User.ID()]
+	g.RUnlock()
+	if ok {
+		return c
+	}
+
+	channels := g.Channels()
+	if len(channels) == 0 {
+		return 0
+	}
+
+	c = m.client.ses.State.UserColor(m.User.ID(), channels[0].ID())
+	g.Lock()
 	g.Colors[m.User.ID()] = c
+	g.Unlock()
 	return c
 }
 
 func (m *DiscordMember) Ban(days int) error {
-	return m.client.ses.GuildBanCreate(m.GuildID(), m.User.ID(), days)
+	return m.BanWithReason("", days)
 }
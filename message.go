@@ -1,10 +1,12 @@
 package dgofw
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -13,8 +15,13 @@ type DiscordMessage struct {
 	sync.RWMutex
 	keys     []string
 	vals     []string
+	args     []string
+	flags    map[string]string
+	files    map[string]*DiscordAttachment
 	m        *discordgo.Message
 	client   *DiscordClient
+	channel  *DiscordChannel
+	member   *DiscordMember
 	Author   *DiscordUser
 	Mentions []*DiscordUser
 }
@@ -28,12 +35,14 @@ func (m *DiscordMessage) ChannelID() string {
 }
 
 func (m *DiscordMessage) GuildID() string {
-	return m.Channel().GuildID()
+	if ch := m.Channel(); ch != nil {
+		return ch.GuildID()
+	}
+	return ""
 }
 
 func (m *DiscordMessage) Timestamp() string {
-	result, _ := m.m.Timestamp.Parse()
-	return result.String()
+	return m.m.Timestamp.String()
 }
 
 func (m *DiscordMessage) Content() string {
@@ -48,75 +57,82 @@ func NewDiscordMessage(client *DiscordClient, m *discordgo.Message) *DiscordMess
 	result := &DiscordMessage{
 		keys:     make([]string, 0),
 		vals:     make([]string, 0),
+		args:     make([]string, 0),
+		flags:    make(map[string]string),
+		files:    make(map[string]*DiscordAttachment),
 		m:        m,
 		client:   client,
-		Author:   NewDiscordUser(client, m.Author),
+		Author:   client.Cache.UpdateUser(m.Author),
 		Mentions: make([]*DiscordUser, 0),
 	}
 
 	if len(m.Mentions) > 0 {
 		result.Mentions = make([]*DiscordUser, len(m.Mentions))
 		for i, u := range m.Mentions {
-			result.Mentions[i] = NewDiscordUser(client, u)
+			result.Mentions[i] = client.Cache.UpdateUser(u)
 		}
 	}
 	return result
 }
 
+// IsMod reports whether the author can manage the server, or everything in the channel.
 func (m *DiscordMessage) IsMod() bool {
-	perms, _ := m.client.ses.State.UserChannelPermissions(m.Author.ID(), m.ChannelID())
+	mem := m.Member()
+	if mem == nil {
+		return false
+	}
 
-	return ((perms & discordgo.PermissionAll) == discordgo.PermissionAll) ||
-		((perms & discordgo.PermissionAdministrator) == discordgo.PermissionAdministrator) ||
-		((perms & discordgo.PermissionManageServer) == discordgo.PermissionManageServer) ||
-		((perms & discordgo.PermissionAllChannel) == discordgo.PermissionAllChannel)
+	perms := mem.PermissionsIn(m.Channel())
+	return perms.Has(PermissionAdministrator) ||
+		perms.Has(PermissionManageGuild) ||
+		perms.Has(Permissions(discordgo.PermissionAllChannel))
 }
 
+// Reply replies to the message with ``msg``. Whether the author is pinged
+// follows the client's ``AllowedMentions`` policy.
+//
+// Messages longer than ``MessageLimit`` are split, and the last part is returned.
 func (m *DiscordMessage) Reply(msg string) *DiscordMessage {
-	m2, err := m.client.ses.ChannelMessageSend(m.ChannelID(), msg)
+	if utf8.RuneCountInString(msg) > MessageLimit {
+		return last(m.ReplySplit(msg, nil))
+	}
+	return m.reply(m.NewReply().Content(msg))
+}
+
+// reply sends a reply built from ``b``, reporting the error to ``OnError`` if it fails.
+func (m *DiscordMessage) reply(b *MessageBuilder) *DiscordMessage {
+	m2, err := b.Send()
 	if err != nil {
-		fmt.Println(err)
+		m.client.reportError(err)
 		return nil
 	}
-	return NewDiscordMessage(m.client, m2)
+	return m2
 }
 
 func (m *DiscordMessage) ReplyComplex(m2 *discordgo.MessageSend) *DiscordMessage {
-	m3, err := m.client.ses.ChannelMessageSendComplex(m.ChannelID(), m2)
-	if err != nil {
-		return nil
+	b := m.NewReply().Content(m2.Content).Embed(m2.Embed).TTS(m2.TTS)
+	if m2.File != nil {
+		b.files = append(b.files, m2.File)
 	}
-	return NewDiscordMessage(m.client, m3)
+	b.files = append(b.files, m2.Files...)
+	return m.reply(b)
 }
 
 func (m *DiscordMessage) ReplyEmbed(embed *discordgo.MessageEmbed) *DiscordMessage {
-	m2, err := m.client.ses.ChannelMessageSendEmbed(m.ChannelID(), embed)
-	if err != nil {
-		fmt.Println(err)
-		return nil
-	}
-	return NewDiscordMessage(m.client, m2)
+	return m.reply(m.NewReply().Embed(embed))
 }
 
 func (m *DiscordMessage) ReplyFile(name string, file io.Reader) *DiscordMessage {
-	m2, err := m.client.ses.ChannelFileSend(m.ChannelID(), name, file)
-	if err != nil {
-		fmt.Println(err)
-		return nil
-	}
-	return NewDiscordMessage(m.client, m2)
+	return m.reply(m.NewReply().File(name, file))
 }
 
 func (m *DiscordMessage) ReplyFileWithMessage(msg, name string, file io.Reader) *DiscordMessage {
-	m2, err := m.client.ses.ChannelFileSendWithMessage(m.ChannelID(), msg, name, file)
-	if err != nil {
-		fmt.Println(err)
-		return nil
-	}
-	return NewDiscordMessage(m.client, m2)
+	return m.reply(m.NewReply().Content(msg).File(name, file))
 }
 
 func (m *DiscordMessage) Arg(key string) string {
+	m.RLock()
+	defer m.RUnlock()
 	for i, s := range m.keys {
 		if s == key {
 			return m.vals[i]
@@ -140,7 +156,7 @@ func (m *DiscordMessage) EditEmbed(embed *discordgo.MessageEmbed) {
 	})
 
 	if err != nil {
-		fmt.Println(err)
+		m.client.reportError(err)
 	}
 }
 
@@ -151,7 +167,7 @@ func (m *DiscordMessage) Delete() {
 func (m *DiscordMessage) DeleteManyIDs(ids ...string) {
 	err := m.client.ses.ChannelMessagesBulkDelete(m.ChannelID(), ids)
 	if err != nil {
-		fmt.Println(err)
+		m.client.reportError(err)
 	}
 }
 
@@ -164,6 +180,8 @@ func (m *DiscordMessage) DeleteMany(msgs ...*DiscordMessage) {
 }
 
 func (m *DiscordMessage) Pairs(keys, vals []string) {
+	m.Lock()
+	defer m.Unlock()
 	m.keys = make([]string, len(keys))
 	m.vals = make([]string, len(vals))
 	for i, key := range keys {
@@ -187,15 +205,42 @@ func (m *DiscordMessage) Pairs(keys, vals []string) {
 	}
 }
 
+// Channel returns the channel the message was sent in. It is looked up on first use.
 func (m *DiscordMessage) Channel() *DiscordChannel {
-	if c := m.client.Cache.GetChannel(m.ChannelID()); c != nil {
-		return c
+	m.RLock()
+	ch := m.channel
+	m.RUnlock()
+	if ch != nil {
+		return ch
 	}
-	c, err := m.client.ses.Channel(m.ChannelID())
-	if err != nil {
+
+	ch = m.client.Cache.GetChannel(m.ChannelID())
+	m.Lock()
+	m.channel = ch
+	m.Unlock()
+	return ch
+}
+
+// Member returns the author as a member of the guild the message was sent in,
+// or nil for private messages. It is looked up on first use.
+func (m *DiscordMessage) Member() *DiscordMember {
+	m.RLock()
+	mem := m.member
+	m.RUnlock()
+	if mem != nil {
+		return mem
+	}
+
+	guild := m.GuildID()
+	if guild == "" {
 		return nil
 	}
-	return NewDiscordChannel(m.client, c)
+
+	mem = m.client.Cache.GetMember(guild, m.Author.ID())
+	m.Lock()
+	m.member = mem
+	m.Unlock()
+	return mem
 }
 
 func (m *DiscordMessage) PrintPairs() {
@@ -206,11 +251,7 @@ func (m *DiscordMessage) PrintPairs() {
 
 func (m *DiscordMessage) Guild() *DiscordGuild {
 	if ch := m.Channel(); ch != nil {
-		if gm := m.client.Cache.GetGuild(ch.GuildID()); gm != nil {
-			return gm
-		}
-		g, _ := m.client.ses.Guild(ch.GuildID())
-		return NewDiscordGuild(m.client, g)
+		return ch.Guild()
 	}
 	return nil
 }
@@ -224,8 +265,70 @@ func (m *DiscordMessage) React(emoji string) {
 	m.client.ses.MessageReactionAdd(m.ChannelID(), m.ID(), emoji)
 }
 
+// RemoveReaction removes the reaction of the author of the message.
 func (m *DiscordMessage) RemoveReaction(emoji string) {
-	m.client.ses.MessageReactionRemove(m.ChannelID(), m.ID(), emoji, m.Author.ID())
+	if err := m.RemoveUserReaction(emoji, m.Author.ID()); err != nil {
+		m.client.reportError(err)
+	}
+}
+
+// RemoveOwnReaction removes the reaction of the bot.
+func (m *DiscordMessage) RemoveOwnReaction(emoji string) error {
+	return m.RemoveUserReaction(emoji, "@me")
+}
+
+// RemoveUserReaction removes the reaction of a user, ``@me`` being the bot.
+func (m *DiscordMessage) RemoveUserReaction(emoji, user string) error {
+	return m.client.ses.MessageReactionRemove(m.ChannelID(), m.ID(), emoji, user)
+}
+
+// RemoveEmojiReactions removes all reactions with one emoji.
+func (m *DiscordMessage) RemoveEmojiReactions(emoji string) error {
+	uri := discordgo.EndpointMessageReactions(m.ChannelID(), m.ID(), emoji)
+	_, err := m.client.ses.RequestWithBucketID("DELETE", uri, nil, discordgo.EndpointMessageReactionsAll(m.ChannelID(), m.ID()))
+	return err
+}
+
+func (m *DiscordMessage) RemoveAllReactions() error {
+	return m.client.ses.MessageReactionsRemoveAll(m.ChannelID(), m.ID())
+}
+
+func (m *DiscordMessage) Pin() error {
+	return m.client.ses.ChannelMessagePin(m.ChannelID(), m.ID())
+}
+
+func (m *DiscordMessage) Unpin() error {
+	return m.client.ses.ChannelMessageUnpin(m.ChannelID(), m.ID())
+}
+
+// Crosspost publishes a message in an announcement channel to the channels following it.
+func (m *DiscordMessage) Crosspost() (*DiscordMessage, error) {
+	uri := discordgo.EndpointChannelMessage(m.ChannelID(), m.ID()) + "/crosspost"
+	res, err := m.client.ses.RequestWithBucketID("POST", uri, nil, discordgo.EndpointChannelMessages(m.ChannelID())+"/crosspost")
+	if err != nil {
+		return nil, err
+	}
+
+	m2 := new(discordgo.Message)
+	if err = json.Unmarshal(res, m2); err != nil {
+		return nil, err
+	}
+	return NewDiscordMessage(m.client, m2), nil
+}
+
+// messageFlagSuppressEmbeds hides the embeds of a message.
+const messageFlagSuppressEmbeds = 1 << 2
+
+// SuppressEmbeds hides, or shows again, the link embeds of the message.
+func (m *DiscordMessage) SuppressEmbeds(suppress bool) error {
+	flags := 0
+	if suppress {
+		flags = messageFlagSuppressEmbeds
+	}
+
+	uri := discordgo.EndpointChannelMessage(m.ChannelID(), m.ID())
+	_, err := m.client.ses.RequestWithBucketID("PATCH", uri, map[string]interface{}{"flags": flags}, discordgo.EndpointChannelMessages(m.ChannelID()))
+	return err
 }
 
 func (m *DiscordMessage) HasMention() bool {
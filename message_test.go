@@ -0,0 +1,116 @@
+package dgofw
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// countingBackend counts how often objects are written to it.
+type countingBackend struct {
+	*MemoryBackend
+	stores int64
+}
+
+func (b *countingBackend) Store(kind CacheKind, key string, v interface{}) error {
+	atomic.AddInt64(&b.stores, 1)
+	return b.MemoryBackend.Store(kind, key, v)
+}
+
+// newMessageClient returns a client with a guild, its owner, a channel and
+// some members cached, as they would be after the guild streamed in.
+func newMessageClient() (*DiscordClient, *failingTransport) {
+	c, requests := newTestClient()
+	c.ses.State.User = &discordgo.User{ID: "bot"}
+
+	c.Cache.UpdateGuild(testGuild("1"))
+	c.Cache.UpdateChannel(testChannel("2", "1"))
+	c.Cache.UpdateMember(testMember("1", "owner"))
+	c.Cache.UpdateMember(testMember("1", "3"))
+	c.Cache.UpdateMember(testMember("1", "4"))
+	return c, requests
+}
+
+func testMessage() *discordgo.Message {
+	return &discordgo.Message{
+		ID:        "5",
+		ChannelID: "2",
+		Content:   "hello <@4>",
+		Author:    testMember("1", "3").User,
+		Mentions:  []*discordgo.User{testMember("1", "4").User},
+	}
+}
+
+func BenchmarkNewDiscordMessage(b *testing.B) {
+	c, requests := newMessageClient()
+	raw := testMessage()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := NewDiscordMessage(c, raw)
+		m.Channel()
+		m.Guild().Owner()
+		m.Member()
+	}
+	b.StopTimer()
+
+	if n := requests.count(); n != 0 {
+		b.Fatalf("made %d REST calls, want 0", n)
+	}
+}
+
+func BenchmarkMessageCreate(b *testing.B) {
+	c, requests := newMessageClient()
+	c.OnMessage("!ping", false, func(*DiscordMessage) {}).In("other")
+	event := &discordgo.MessageCreate{Message: testMessage()}
+	event.Content = "!ping"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.handleMessageC(c.ses, event)
+	}
+	b.StopTimer()
+
+	if n := requests.count(); n != 0 {
+		b.Fatalf("made %d REST calls, want 0", n)
+	}
+}
+
+func TestNewDiscordMessageNoRequests(t *testing.T) {
+	c, requests := newMessageClient()
+	m := NewDiscordMessage(c, testMessage())
+
+	if m.GuildID() != "1" {
+		t.Errorf("got guild %q, want 1", m.GuildID())
+	}
+	if owner := m.Guild().Owner(); owner == nil || owner.User.ID() != "owner" {
+		t.Error("owner not found")
+	}
+	if mem := m.Member(); mem == nil || mem.User.ID() != "3" {
+		t.Error("member not found")
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d REST calls, want 0", n)
+	}
+}
+
+func TestNewDiscordMessageSkipsUnchangedUsers(t *testing.T) {
+	c, _ := newMessageClient()
+	backend := &countingBackend{MemoryBackend: NewMemoryBackend()}
+	c.Cache.SetBackend(backend)
+
+	NewDiscordMessage(c, testMessage())
+	if n := atomic.LoadInt64(&backend.stores); n != 0 {
+		t.Errorf("stored %d unchanged users", n)
+	}
+
+	raw := testMessage()
+	raw.Author.Username = "renamed"
+	NewDiscordMessage(c, raw)
+	if n := atomic.LoadInt64(&backend.stores); n != 1 {
+		t.Errorf("stored %d users, want the renamed author only", n)
+	}
+}
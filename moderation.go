@@ -0,0 +1,155 @@
+package dgofw
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxTimeout is the longest a member can be timed out for.
+const MaxTimeout = 28 * 24 * time.Hour
+
+// ErrTimeoutTooLong is returned for timeouts longer than ``MaxTimeout``.
+var ErrTimeoutTooLong = errors.New("timeouts can last at most 28 days")
+
+// auditReason sends an audit log reason along with a request. Discord only reads
+// it from the ``X-Audit-Log-Reason`` header, URL encoded.
+func auditReason(reason string) []discordgo.RequestOption {
+	if reason == "" {
+		return nil
+	}
+	return []discordgo.RequestOption{discordgo.WithAuditLogReason(url.PathEscape(reason))}
+}
+
+// edit patches the member. Fields set to nil are cleared.
+func (m *DiscordMember) edit(data map[string]interface{}, reason string) error {
+	uri := discordgo.EndpointGuildMember(m.GuildID(), m.User.ID())
+	_, err := m.client.ses.RequestWithBucketID("PATCH", uri, data, discordgo.EndpointGuildMember(m.GuildID(), ""), auditReason(reason)...)
+	return err
+}
+
+// Kick removes the member from the guild.
+func (m *DiscordMember) Kick(reason string) error {
+	if err := m.client.checkBotModerate(m, PermissionKickMembers); err != nil {
+		return err
+	}
+	return m.client.ses.GuildMemberDelete(m.GuildID(), m.User.ID(), auditReason(reason)...)
+}
+
+// BanWithReason bans the member, deleting their messages of the last ``days`` days.
+func (m *DiscordMember) BanWithReason(reason string, days int) error {
+	if err := m.client.checkBotModerate(m, PermissionBanMembers); err != nil {
+		return err
+	}
+	return m.client.ses.GuildBanCreate(m.GuildID(), m.User.ID(), days, auditReason(reason)...)
+}
+
+// Timeout stops the member from talking, reacting and joining voice channels for ``d``.
+func (m *DiscordMember) Timeout(d time.Duration, reason string) error {
+	if d > MaxTimeout {
+		return ErrTimeoutTooLong
+	}
+	if d <= 0 {
+		return m.Untimeout(reason)
+	}
+	if err := m.client.checkBotModerate(m, PermissionModerateMembers); err != nil {
+		return err
+	}
+
+	until := time.Now().Add(d).UTC().Format(time.RFC3339)
+	return m.edit(map[string]interface{}{"communication_disabled_until": until}, reason)
+}
+
+// Untimeout lifts a timeout early.
+func (m *DiscordMember) Untimeout(reason string) error {
+	if err := m.client.checkBotModerate(m, PermissionModerateMembers); err != nil {
+		return err
+	}
+	return m.edit(map[string]interface{}{"communication_disabled_until": nil}, reason)
+}
+
+// SetNickname changes the nickname of the member, an empty nickname resets it.
+func (m *DiscordMember) SetNickname(nick, reason string) error {
+	if m.User.ID() == m.client.ses.State.User.ID {
+		if err := m.client.checkBot(m.GuildID(), PermissionChangeNickname); err != nil {
+			return err
+		}
+		// The own nickname has its own endpoint, and no hierarchy applies.
+		return m.client.ses.GuildMemberNickname(m.GuildID(), "@me", nick)
+	}
+
+	if err := m.client.checkBotModerate(m, PermissionManageNicknames); err != nil {
+		return err
+	}
+	return m.edit(map[string]interface{}{"nick": nick}, reason)
+}
+
+func (m *DiscordMember) ClearNickname(reason string) error {
+	return m.SetNickname("", reason)
+}
+
+// Deafen server deafens, or undeafens, the member.
+func (m *DiscordMember) Deafen(deaf bool, reason string) error {
+	if err := m.client.checkBot(m.GuildID(), PermissionDeafenMembers); err != nil {
+		return err
+	}
+	return m.edit(map[string]interface{}{"deaf": deaf}, reason)
+}
+
+// Mute server mutes, or unmutes, the member.
+func (m *DiscordMember) Mute(mute bool, reason string) error {
+	if err := m.client.checkBot(m.GuildID(), PermissionMuteMembers); err != nil {
+		return err
+	}
+	return m.edit(map[string]interface{}{"mute": mute}, reason)
+}
+
+// Move moves the member to another voice channel.
+// The member has to be connected to voice already.
+func (m *DiscordMember) Move(channel, reason string) error {
+	if err := m.client.checkBot(m.GuildID(), PermissionMoveMembers); err != nil {
+		return err
+	}
+	return m.edit(map[string]interface{}{"channel_id": channel}, reason)
+}
+
+// Disconnect disconnects the member from voice.
+func (m *DiscordMember) Disconnect(reason string) error {
+	if err := m.client.checkBot(m.GuildID(), PermissionMoveMembers); err != nil {
+		return err
+	}
+	return m.edit(map[string]interface{}{"channel_id": nil}, reason)
+}
+
+func (g *DiscordGuild) Kick(user, reason string) error {
+	if m := g.client.Cache.GetMember(g.ID(), user); m != nil {
+		return m.Kick(reason)
+	}
+	return g.client.ses.GuildMemberDelete(g.ID(), user, auditReason(reason)...)
+}
+
+// Ban bans a user, who doesn't have to be a member of the guild.
+func (g *DiscordGuild) Ban(user, reason string, days int) error {
+	if m := g.client.Cache.GetMember(g.ID(), user); m != nil {
+		return m.BanWithReason(reason, days)
+	}
+	if err := g.client.checkBot(g.ID(), PermissionBanMembers); err != nil {
+		return err
+	}
+	return g.client.ses.GuildBanCreate(g.ID(), user, days, auditReason(reason)...)
+}
+
+func (g *DiscordGuild) Unban(user, reason string) error {
+	if err := g.client.checkBot(g.ID(), PermissionBanMembers); err != nil {
+		return err
+	}
+	uri := discordgo.EndpointGuildBan(g.ID(), user)
+	_, err := g.client.ses.RequestWithBucketID("DELETE", uri, nil, discordgo.EndpointGuildBan(g.ID(), ""), auditReason(reason)...)
+	return err
+}
+
+func (g *DiscordGuild) Bans() ([]*discordgo.GuildBan, error) {
+	return g.client.ses.GuildBans(g.ID(), 0, "", "")
+}
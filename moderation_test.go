@@ -0,0 +1,132 @@
+package dgofw
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// reasonTransport records the audit log reason of every request it gets.
+// Lookups fail, so nothing but the test's own requests succeed.
+type reasonTransport struct {
+	sync.Mutex
+	requests []string
+}
+
+func (t *reasonTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"id": "2", "guild_id": "1", "name": "c"}`)),
+		Request:    r,
+	}
+	if r.Method == "GET" {
+		res.StatusCode = http.StatusNotFound
+		res.Body = ioutil.NopCloser(strings.NewReader(`{"message": "Unknown", "code": 10000}`))
+		return res, nil
+	}
+
+	t.Lock()
+	t.requests = append(t.requests, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Audit-Log-Reason"))
+	t.Unlock()
+	return res, nil
+}
+
+func (t *reasonTransport) last() string {
+	t.Lock()
+	defer t.Unlock()
+	if len(t.requests) == 0 {
+		return ""
+	}
+	return t.requests[len(t.requests)-1]
+}
+
+func TestAuditLogReason(t *testing.T) {
+	c, _ := newMessageClient()
+	transport := new(reasonTransport)
+	c.ses.Client = &http.Client{Transport: transport}
+	g := c.Cache.GetGuild("1")
+	m := c.Cache.GetMember("1", "3")
+	ch := c.Cache.GetChannel("2")
+
+	tests := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{"kick", func() error { return m.Kick("spam") }, "DELETE /api/v9/guilds/1/members/3 spam"},
+		{"ban", func() error { return m.BanWithReason("spam", 1) }, "PUT /api/v9/guilds/1/bans/3?delete_message_days=1 spam"},
+		{"unban", func() error { return g.Unban("3", "appeal") }, "DELETE /api/v9/guilds/1/bans/3 appeal"},
+		{"nickname", func() error { return m.SetNickname("n", "bad name") }, "PATCH /api/v9/guilds/1/members/3 bad%20name"},
+		{"no reason", func() error { return m.SetNickname("n", "") }, "PATCH /api/v9/guilds/1/members/3 "},
+		{"channel edit", func() error { return ch.Edit(ChannelOptions{Name: "c", Reason: "tidy"}) }, "PATCH /api/v9/channels/2 tidy"},
+		{"create channel", func() error {
+			_, err := g.CreateChannel(ChannelOptions{Name: "c", Reason: "new"})
+			return err
+		}, "POST /api/v9/guilds/1/channels new"},
+		{"invite", func() error { return g.DeleteInvite("abc", "leaked") }, "DELETE /api/v9/invites/abc leaked"},
+		{"thread", func() error { return ch.Archive("done") }, "PATCH /api/v9/channels/2 done"},
+		{"channel delete", func() error { return ch.Delete("ünused") }, "DELETE /api/v9/channels/2 %C3%BCnused"},
+	}
+
+	for _, tt := range tests {
+		if err := tt.call(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := transport.last(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestTimeoutModerateMembers checks the permission above the 32 bit range,
+// with a bot whose only permission is Moderate Members.
+func TestTimeoutModerateMembers(t *testing.T) {
+	c, _ := newMessageClient()
+	transport := new(reasonTransport)
+	c.ses.Client = &http.Client{Transport: transport}
+
+	g := testGuild("1")
+	g.Roles = []*discordgo.Role{
+		{ID: "1"},
+		{ID: "10", Position: 2, Permissions: int64(PermissionModerateMembers)},
+		{ID: "11", Position: 1},
+	}
+	c.Cache.UpdateGuild(g)
+
+	bot := testMember("1", "bot")
+	bot.Roles = []string{"10"}
+	c.Cache.UpdateMember(bot)
+	target := testMember("1", "3")
+	target.Roles = []string{"11"}
+	m := c.Cache.UpdateMember(target)
+
+	if p := c.BotMember("1").Permissions(); p != PermissionModerateMembers {
+		t.Fatalf("got permissions %s, want only Moderate Members", p)
+	}
+	if err := m.Timeout(time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Untimeout(""); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(transport.requests); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+
+	// Without the role the bot can't time out anyone.
+	bot.Roles = nil
+	c.Cache.UpdateMember(bot)
+	if err := m.Timeout(time.Hour, ""); err != ErrMissingPermission {
+		t.Errorf("got %v, want ErrMissingPermission", err)
+	}
+	if n := len(transport.requests); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
@@ -0,0 +1,188 @@
+package dgofw
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permission overwrite target types.
+const (
+	OverwriteRole   = discordgo.PermissionOverwriteTypeRole
+	OverwriteMember = discordgo.PermissionOverwriteTypeMember
+)
+
+// ErrNoParent is returned when a channel has to be in a category, but isn't.
+var ErrNoParent = errors.New("channel has no parent category")
+
+// Overwrite allows or denies permissions in a channel for a role or member.
+type Overwrite struct {
+	ID    string
+	Type  discordgo.PermissionOverwriteType
+	Allow Permissions
+	Deny  Permissions
+}
+
+func newOverwrite(o *discordgo.PermissionOverwrite) *Overwrite {
+	return &Overwrite{
+		ID:    o.ID,
+		Type:  o.Type,
+		Allow: Permissions(o.Allow),
+		Deny:  Permissions(o.Deny),
+	}
+}
+
+func (o *Overwrite) raw() *discordgo.PermissionOverwrite {
+	return &discordgo.PermissionOverwrite{
+		ID:    o.ID,
+		Type:  o.Type,
+		Allow: int64(o.Allow),
+		Deny:  int64(o.Deny),
+	}
+}
+
+// Overwrites returns the permission overwrites of the channel.
+func (c *DiscordChannel) Overwrites() []*Overwrite {
+	iter := c.raw().PermissionOverwrites
+	result := make([]*Overwrite, len(iter))
+	for i, o := range iter {
+		result[i] = newOverwrite(o)
+	}
+	return result
+}
+
+// Overwrite returns the overwrite for a role or member, or nil.
+func (c *DiscordChannel) Overwrite(id string) *Overwrite {
+	for _, o := range c.raw().PermissionOverwrites {
+		if o.ID == id {
+			return newOverwrite(o)
+		}
+	}
+	return nil
+}
+
+// setOverwrites stores changed overwrites in the cache, without waiting for the channel update event.
+func (c *DiscordChannel) setOverwrites(overwrites []*discordgo.PermissionOverwrite) {
+	raw := *c.raw()
+	raw.PermissionOverwrites = overwrites
+	c.client.Cache.UpdateChannel(&raw)
+}
+
+// SetOverwrite creates or replaces an overwrite.
+func (c *DiscordChannel) SetOverwrite(o *Overwrite) error {
+	if err := c.client.ses.ChannelPermissionSet(c.ID(), o.ID, o.Type, int64(o.Allow), int64(o.Deny)); err != nil {
+		return err
+	}
+
+	iter := c.raw().PermissionOverwrites
+	result := make([]*discordgo.PermissionOverwrite, 0, len(iter)+1)
+	for _, old := range iter {
+		if old.ID != o.ID {
+			result = append(result, old)
+		}
+	}
+	c.setOverwrites(append(result, o.raw()))
+	return nil
+}
+
+func (c *DiscordChannel) SetRoleOverwrite(role string, allow, deny Permissions) error {
+	return c.SetOverwrite(&Overwrite{
+		ID:    role,
+		Type:  OverwriteRole,
+		Allow: allow,
+		Deny:  deny,
+	})
+}
+
+func (c *DiscordChannel) SetMemberOverwrite(user string, allow, deny Permissions) error {
+	return c.SetOverwrite(&Overwrite{
+		ID:    user,
+		Type:  OverwriteMember,
+		Allow: allow,
+		Deny:  deny,
+	})
+}
+
+// DeleteOverwrite removes the overwrite for a role or member.
+func (c *DiscordChannel) DeleteOverwrite(id string) error {
+	if err := c.client.ses.ChannelPermissionDelete(c.ID(), id); err != nil {
+		return err
+	}
+
+	iter := c.raw().PermissionOverwrites
+	result := make([]*discordgo.PermissionOverwrite, 0, len(iter))
+	for _, o := range iter {
+		if o.ID != id {
+			result = append(result, o)
+		}
+	}
+	c.setOverwrites(result)
+	return nil
+}
+
+// everyone returns the ``@everyone`` overwrite, or an empty one.
+func (c *DiscordChannel) everyone() *Overwrite {
+	if o := c.Overwrite(c.GuildID()); o != nil {
+		return o
+	}
+	return &Overwrite{
+		ID:   c.GuildID(),
+		Type: OverwriteRole,
+	}
+}
+
+// Lock stops ``@everyone`` from sending messages in the channel,
+// keeping the rest of its overwrite.
+func (c *DiscordChannel) Lock() error {
+	o := c.everyone()
+	o.Allow = o.Allow.Remove(PermissionSendMessages)
+	o.Deny = o.Deny.Add(PermissionSendMessages)
+	return c.SetOverwrite(o)
+}
+
+// Unlock undoes ``Lock``. The permission is inherited again rather than allowed explicitly.
+func (c *DiscordChannel) Unlock() error {
+	o := c.Overwrite(c.GuildID())
+	if o == nil {
+		return nil
+	}
+
+	o.Deny = o.Deny.Remove(PermissionSendMessages)
+	if o.Allow == 0 && o.Deny == 0 {
+		return c.DeleteOverwrite(o.ID)
+	}
+	return c.SetOverwrite(o)
+}
+
+// SyncWithCategory replaces the overwrites of the channel with those of its category.
+func (c *DiscordChannel) SyncWithCategory() error {
+	parent := c.ParentID()
+	if parent == "" {
+		return ErrNoParent
+	}
+
+	cat := c.client.Cache.GetChannel(parent)
+	if cat == nil {
+		return ErrNoParent
+	}
+
+	overwrites := cat.raw().PermissionOverwrites
+	if overwrites == nil {
+		overwrites = make([]*discordgo.PermissionOverwrite, 0)
+	}
+
+	// ChannelEdit omits empty overwrites, so clearing them wouldn't work.
+	data := map[string]interface{}{"permission_overwrites": overwrites}
+	res, err := c.client.ses.RequestWithBucketID("PATCH", discordgo.EndpointChannel(c.ID()), data, discordgo.EndpointChannel(c.ID()))
+	if err != nil {
+		return err
+	}
+
+	ch := new(discordgo.Channel)
+	if err = json.Unmarshal(res, ch); err != nil {
+		return err
+	}
+	c.client.Cache.UpdateChannel(ch)
+	return nil
+}
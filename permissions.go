@@ -0,0 +1,247 @@
+package dgofw
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permissions is a permission bitset.
+type Permissions int64
+
+const (
+	PermissionCreateInstantInvite Permissions = 1 << iota
+	PermissionKickMembers
+	PermissionBanMembers
+	PermissionAdministrator
+	PermissionManageChannels
+	PermissionManageGuild
+	PermissionAddReactions
+	PermissionViewAuditLog
+	PermissionPrioritySpeaker
+	PermissionStream
+	PermissionViewChannel
+	PermissionSendMessages
+	PermissionSendTTSMessages
+	PermissionManageMessages
+	PermissionEmbedLinks
+	PermissionAttachFiles
+	PermissionReadMessageHistory
+	PermissionMentionEveryone
+	PermissionUseExternalEmojis
+	PermissionViewGuildInsights
+	PermissionConnect
+	PermissionSpeak
+	PermissionMuteMembers
+	PermissionDeafenMembers
+	PermissionMoveMembers
+	PermissionUseVAD
+	PermissionChangeNickname
+	PermissionManageNicknames
+	PermissionManageRoles
+	PermissionManageWebhooks
+	PermissionManageEmojis
+	PermissionUseApplicationCommands
+	PermissionRequestToSpeak
+	PermissionManageEvents
+	PermissionManageThreads
+	PermissionCreatePublicThreads
+	PermissionCreatePrivateThreads
+	PermissionUseExternalStickers
+	PermissionSendMessagesInThreads
+	PermissionUseEmbeddedActivities
+	PermissionModerateMembers
+
+	// PermissionAll has every permission set.
+	PermissionAll = PermissionModerateMembers<<1 - 1
+)
+
+var permissionNames = []string{
+	"Create Instant Invite",
+	"Kick Members",
+	"Ban Members",
+	"Administrator",
+	"Manage Channels",
+	"Manage Server",
+	"Add Reactions",
+	"View Audit Log",
+	"Priority Speaker",
+	"Video",
+	"View Channel",
+	"Send Messages",
+	"Send TTS Messages",
+	"Manage Messages",
+	"Embed Links",
+	"Attach Files",
+	"Read Message History",
+	"Mention Everyone",
+	"Use External Emojis",
+	"View Server Insights",
+	"Connect",
+	"Speak",
+	"Mute Members",
+	"Deafen Members",
+	"Move Members",
+	"Use Voice Activity",
+	"Change Nickname",
+	"Manage Nicknames",
+	"Manage Roles",
+	"Manage Webhooks",
+	"Manage Emojis",
+	"Use Application Commands",
+	"Request to Speak",
+	"Manage Events",
+	"Manage Threads",
+	"Create Public Threads",
+	"Create Private Threads",
+	"Use External Stickers",
+	"Send Messages in Threads",
+	"Use Activities",
+	"Timeout Members",
+}
+
+// Has reports whether all permissions in ``perm`` are set.
+func (p Permissions) Has(perm Permissions) bool {
+	return p&perm == perm
+}
+
+func (p Permissions) Add(perm Permissions) Permissions {
+	return p | perm
+}
+
+func (p Permissions) Remove(perm Permissions) Permissions {
+	return p &^ perm
+}
+
+// Names returns the human readable names of the set permissions.
+func (p Permissions) Names() []string {
+	result := make([]string, 0)
+	for i, name := range permissionNames {
+		if p&(1<<uint(i)) != 0 {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func (p Permissions) String() string {
+	return strings.Join(p.Names(), ", ")
+}
+
+// computePermissions resolves the permissions of a member in a guild,
+// and in a channel if ``overwrites`` is not nil.
+func computePermissions(guild, owner string, roles []*discordgo.Role, m *discordgo.Member, overwrites []*discordgo.PermissionOverwrite) Permissions {
+	if m.User.ID == owner {
+		return PermissionAll
+	}
+
+	var perms Permissions
+	for _, r := range roles {
+		if r.ID == guild {
+			perms |= Permissions(r.Permissions)
+			break
+		}
+	}
+
+	for _, id := range m.Roles {
+		for _, r := range roles {
+			if r.ID == id {
+				perms |= Permissions(r.Permissions)
+				break
+			}
+		}
+	}
+
+	if perms.Has(PermissionAdministrator) {
+		return PermissionAll
+	}
+
+	if overwrites == nil {
+		return perms
+	}
+
+	// @everyone first, then all roles of the member at once, then the member itself.
+	for _, o := range overwrites {
+		if o.ID == guild {
+			perms = perms&^Permissions(o.Deny) | Permissions(o.Allow)
+			break
+		}
+	}
+
+	var allow, deny Permissions
+	for _, o := range overwrites {
+		if o.Type != OverwriteRole {
+			continue
+		}
+		for _, id := range m.Roles {
+			if o.ID == id {
+				allow |= Permissions(o.Allow)
+				deny |= Permissions(o.Deny)
+				break
+			}
+		}
+	}
+	perms = perms&^deny | allow
+
+	for _, o := range overwrites {
+		if o.Type == OverwriteMember && o.ID == m.User.ID {
+			perms = perms&^Permissions(o.Deny) | Permissions(o.Allow)
+			break
+		}
+	}
+
+	// Without access to the channel nothing else applies,
+	// and neither does anything that comes with sending messages.
+	if !perms.Has(PermissionViewChannel) {
+		return 0
+	}
+	if !perms.Has(PermissionSendMessages) {
+		perms &^= PermissionSendTTSMessages | PermissionMentionEveryone | PermissionEmbedLinks | PermissionAttachFiles
+	}
+	return perms
+}
+
+// Permissions returns the guild wide permissions of the member.
+func (m *DiscordMember) Permissions() Permissions {
+	g := m.Guild()
+	if g == nil {
+		return 0
+	}
+
+	raw := g.raw()
+	return computePermissions(raw.ID, raw.OwnerID, raw.Roles, m.raw(), nil)
+}
+
+// PermissionsIn returns the permissions of the member in a channel of the guild,
+// taking permission overwrites into account.
+//
+// Threads have no overwrites of their own, those of the parent channel are used.
+// If the parent isn't cached the permissions can't be resolved, and are 0.
+func (m *DiscordMember) PermissionsIn(channel *DiscordChannel) Permissions {
+	g := m.Guild()
+	if g == nil || channel == nil {
+		return 0
+	}
+	if channel.IsThread() {
+		if channel = channel.Parent(); channel == nil {
+			return 0
+		}
+	}
+
+	overwrites := channel.raw().PermissionOverwrites
+	if overwrites == nil {
+		overwrites = make([]*discordgo.PermissionOverwrite, 0)
+	}
+
+	raw := g.raw()
+	return computePermissions(raw.ID, raw.OwnerID, raw.Roles, m.raw(), overwrites)
+}
+
+// Can reports whether the member has all permissions in ``perm``,
+// in ``channel`` if it isn't nil.
+func (m *DiscordMember) Can(perm Permissions, channel *DiscordChannel) bool {
+	if channel == nil {
+		return m.Permissions().Has(perm)
+	}
+	return m.PermissionsIn(channel).Has(perm)
+}
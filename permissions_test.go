@@ -0,0 +1,165 @@
+package dgofw
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestComputePermissions(t *testing.T) {
+	const (
+		view = PermissionViewChannel
+		send = PermissionSendMessages
+		base = view | send | PermissionAddReactions
+	)
+	roles := []*discordgo.Role{
+		{ID: "guild", Permissions: int64(base)},
+		{ID: "mods", Permissions: int64(PermissionKickMembers)},
+		{ID: "admins", Permissions: int64(PermissionAdministrator)},
+		{ID: "muted"},
+		{ID: "timeouts", Permissions: int64(PermissionModerateMembers)},
+	}
+	everyone := func(allow, deny Permissions) *discordgo.PermissionOverwrite {
+		return &discordgo.PermissionOverwrite{ID: "guild", Type: OverwriteRole, Allow: int64(allow), Deny: int64(deny)}
+	}
+	role := func(id string, allow, deny Permissions) *discordgo.PermissionOverwrite {
+		return &discordgo.PermissionOverwrite{ID: id, Type: OverwriteRole, Allow: int64(allow), Deny: int64(deny)}
+	}
+	member := func(allow, deny Permissions) *discordgo.PermissionOverwrite {
+		return &discordgo.PermissionOverwrite{ID: "user", Type: OverwriteMember, Allow: int64(allow), Deny: int64(deny)}
+	}
+
+	tests := []struct {
+		name       string
+		user       string
+		roles      []string
+		overwrites []*discordgo.PermissionOverwrite
+		want       Permissions
+	}{
+		{
+			name: "guild wide",
+			user: "user", roles: []string{"mods"},
+			want: base | PermissionKickMembers,
+		},
+		{
+			name: "owner",
+			user: "owner",
+			overwrites: []*discordgo.PermissionOverwrite{
+				everyone(0, view),
+			},
+			want: PermissionAll,
+		},
+		{
+			name: "administrator",
+			user: "user", roles: []string{"admins"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				everyone(0, view),
+				member(0, view),
+			},
+			want: PermissionAll,
+		},
+		{
+			name: "everyone denied, role allowed",
+			user: "user", roles: []string{"mods"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				everyone(0, send),
+				role("mods", send, 0),
+			},
+			want: base | PermissionKickMembers,
+		},
+		{
+			name: "role allow beats role deny",
+			user: "user", roles: []string{"mods", "muted"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				role("muted", 0, send|PermissionAddReactions),
+				role("mods", send, 0),
+			},
+			want: view | send | PermissionKickMembers,
+		},
+		{
+			name: "member beats role",
+			user: "user", roles: []string{"mods"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				role("mods", PermissionManageMessages, 0),
+				member(0, PermissionManageMessages|PermissionAddReactions),
+			},
+			want: view | send | PermissionKickMembers,
+		},
+		{
+			name: "moderate members only",
+			user: "user", roles: []string{"timeouts"},
+			want: base | PermissionModerateMembers,
+		},
+		{
+			name: "moderate members with overwrites",
+			user: "user", roles: []string{"timeouts"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				everyone(0, send),
+			},
+			want: view | PermissionAddReactions | PermissionModerateMembers,
+		},
+		{
+			name: "no view channel",
+			user: "user", roles: []string{"mods"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				everyone(0, view),
+			},
+			want: 0,
+		},
+		{
+			name: "no send messages",
+			user: "user", roles: []string{"mods"},
+			overwrites: []*discordgo.PermissionOverwrite{
+				everyone(PermissionEmbedLinks|PermissionAttachFiles|PermissionMentionEveryone, send),
+			},
+			want: view | PermissionAddReactions | PermissionKickMembers,
+		},
+	}
+
+	for _, tt := range tests {
+		m := &discordgo.Member{User: &discordgo.User{ID: tt.user}, Roles: tt.roles}
+		if got := computePermissions("guild", "owner", roles, m, tt.overwrites); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionsInThread(t *testing.T) {
+	c, _ := newTestClient()
+	g := testGuild("1")
+	g.Roles = []*discordgo.Role{{ID: "1", Permissions: int64(PermissionViewChannel)}}
+	c.Cache.UpdateGuild(g)
+
+	parent := testChannel("2", "1")
+	parent.PermissionOverwrites = []*discordgo.PermissionOverwrite{
+		{ID: "1", Type: OverwriteRole, Deny: int64(PermissionViewChannel)},
+	}
+	c.Cache.UpdateChannel(parent)
+
+	thread := testChannel("3", "1")
+	thread.Type = ChannelTypeGuildPublicThread
+	thread.ParentID = "2"
+	c.Cache.UpdateChannel(thread)
+
+	m := c.Cache.UpdateMember(testMember("1", "4"))
+	if p := m.PermissionsIn(c.Cache.GetChannel("3")); p != 0 {
+		t.Errorf("got %s in the thread, want the parent's overwrites to hide it", p)
+	}
+}
+
+func TestModerateNil(t *testing.T) {
+	c, _ := newTestClient()
+	c.Cache.UpdateGuild(testGuild("1"))
+	m := c.Cache.UpdateMember(testMember("1", "4"))
+
+	if BotCanModerate(nil) {
+		t.Error("bot can moderate nil")
+	}
+	if err := CheckModerate(nil, m, PermissionKickMembers); err != ErrMissingPermission {
+		t.Errorf("nil actor: got %v, want %v", err, ErrMissingPermission)
+	}
+	owner := c.Cache.UpdateMember(testMember("1", "owner"))
+	if err := CheckModerate(owner, nil, PermissionKickMembers); err != ErrHierarchy {
+		t.Errorf("nil target: got %v, want %v", err, ErrHierarchy)
+	}
+}
@@ -0,0 +1,349 @@
+package dgofw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ReactionRoleMode decides what reacting, and removing a reaction, does.
+type ReactionRoleMode int
+
+const (
+	// ReactionRoleNormal adds the role on react, and removes it again with the reaction.
+	ReactionRoleNormal ReactionRoleMode = iota
+
+	// ReactionRoleUnique is like normal, but a member can only have one of the unique
+	// roles of a message. Picking another one removes the old role and reaction.
+	ReactionRoleUnique
+
+	// ReactionRoleVerify only ever adds the role, removing the reaction does nothing.
+	ReactionRoleVerify
+
+	// ReactionRoleToggle adds or removes the role on every reaction. The reaction is
+	// removed right away so it can be used again.
+	ReactionRoleToggle
+)
+
+// ReactionRole binds a reaction on a message to a role.
+type ReactionRole struct {
+	GuildID   string           `json:"guild_id"`
+	ChannelID string           `json:"channel_id"`
+	MessageID string           `json:"message_id"`
+	Emoji     string           `json:"emoji"`
+	RoleID    string           `json:"role_id"`
+	Mode      ReactionRoleMode `json:"mode"`
+}
+
+func (r *ReactionRole) key() string {
+	return reactionRoleKey(r.MessageID, r.Emoji)
+}
+
+func reactionRoleKey(message, emoji string) string {
+	return message + ":" + emojiKey(emoji)
+}
+
+// emojiKey identifies an emoji given as ``<:name:id>``, ``name:id``, an ID or unicode.
+func emojiKey(emoji string) string {
+	emoji = strings.Trim(emoji, "<>")
+	if i := strings.LastIndexByte(emoji, ':'); i >= 0 {
+		return emoji[i+1:]
+	}
+	return emoji
+}
+
+// apiEmoji turns an emoji into the ``name:id`` form the reaction endpoints expect.
+func apiEmoji(emoji string) string {
+	emoji = strings.Trim(emoji, "<>")
+	emoji = strings.TrimPrefix(emoji, "a:")
+	return strings.TrimPrefix(emoji, ":")
+}
+
+// ReactionRoleStore persists reaction role bindings.
+type ReactionRoleStore interface {
+	Load() ([]*ReactionRole, error)
+	Save(r *ReactionRole) error
+	Delete(r *ReactionRole) error
+}
+
+// FileReactionRoleStore keeps reaction role bindings in a JSON file.
+type FileReactionRoleStore struct {
+	sync.Mutex
+	path  string
+	roles map[string]*ReactionRole
+}
+
+func NewFileReactionRoleStore(path string) *FileReactionRoleStore {
+	return &FileReactionRoleStore{
+		path:  path,
+		roles: make(map[string]*ReactionRole),
+	}
+}
+
+func (s *FileReactionRoleStore) Load() ([]*ReactionRole, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ReactionRole, 0)
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	for _, r := range result {
+		s.roles[r.key()] = r
+	}
+	return result, nil
+}
+
+func (s *FileReactionRoleStore) Save(r *ReactionRole) error {
+	s.Lock()
+	defer s.Unlock()
+	s.roles[r.key()] = r
+	return s.write()
+}
+
+func (s *FileReactionRoleStore) Delete(r *ReactionRole) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.roles, r.key())
+	return s.write()
+}
+
+// write replaces the file with the current bindings, only once they are written completely.
+func (s *FileReactionRoleStore) write() error {
+	roles := make([]*ReactionRole, 0, len(s.roles))
+	for _, r := range s.roles {
+		roles = append(roles, r)
+	}
+
+	data, err := json.MarshalIndent(roles, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// ReactionRoles hands out roles for reactions on messages.
+type ReactionRoles struct {
+	sync.RWMutex
+	client   *DiscordClient
+	store    ReactionRoleStore
+	bindings map[string]*ReactionRole
+}
+
+// NewReactionRoles loads the bindings in ``store`` and starts handling reactions.
+// A nil store keeps the bindings in memory only.
+func (c *DiscordClient) NewReactionRoles(store ReactionRoleStore) (*ReactionRoles, error) {
+	result := &ReactionRoles{
+		client:   c,
+		store:    store,
+		bindings: make(map[string]*ReactionRole),
+	}
+
+	if store != nil {
+		roles, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range roles {
+			result.bindings[r.key()] = r
+		}
+	}
+
+	c.ses.AddHandler(result.handleAdd)
+	c.ses.AddHandler(result.handleRemove)
+	c.ses.AddHandler(result.handleMessageDelete)
+	return result, nil
+}
+
+// Bind hands out ``role`` for reacting with ``emoji`` on the message, and adds the
+// reaction so members can click it.
+func (rr *ReactionRoles) Bind(m *DiscordMessage, emoji, role string, mode ReactionRoleMode) error {
+	guild := m.GuildID()
+	if guild == "" {
+		return errors.New("reaction roles only work in guilds")
+	}
+
+	if g := rr.client.Cache.GetGuild(guild); g == nil || g.Role(role) == nil {
+		return fmt.Errorf("role %s not found", role)
+	}
+	if err := rr.client.checkBotRole(guild, role); err != nil {
+		return err
+	}
+
+	r := &ReactionRole{
+		GuildID:   guild,
+		ChannelID: m.ChannelID(),
+		MessageID: m.ID(),
+		Emoji:     apiEmoji(emoji),
+		RoleID:    role,
+		Mode:      mode,
+	}
+
+	if err := rr.client.ses.MessageReactionAdd(r.ChannelID, r.MessageID, r.Emoji); err != nil {
+		return err
+	}
+
+	if rr.store != nil {
+		if err := rr.store.Save(r); err != nil {
+			return err
+		}
+	}
+
+	rr.Lock()
+	rr.bindings[r.key()] = r
+	rr.Unlock()
+	return nil
+}
+
+// Unbind removes a binding, and the bot's reaction.
+func (rr *ReactionRoles) Unbind(message, emoji string) error {
+	key := reactionRoleKey(message, emoji)
+	rr.Lock()
+	r, ok := rr.bindings[key]
+	delete(rr.bindings, key)
+	rr.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if rr.store != nil {
+		if err := rr.store.Delete(r); err != nil {
+			return err
+		}
+	}
+	return rr.client.ses.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji, "@me")
+}
+
+// Bindings returns the bindings on a message.
+func (rr *ReactionRoles) Bindings(message string) []*ReactionRole {
+	rr.RLock()
+	defer rr.RUnlock()
+	result := make([]*ReactionRole, 0)
+	for _, r := range rr.bindings {
+		if r.MessageID == message {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (rr *ReactionRoles) lookup(r *discordgo.MessageReaction) (*ReactionRole, *DiscordMember) {
+	if r.UserID == rr.client.ses.State.User.ID {
+		return nil, nil
+	}
+
+	emoji := r.Emoji.ID
+	if emoji == "" {
+		emoji = r.Emoji.Name
+	}
+
+	rr.RLock()
+	binding, ok := rr.bindings[reactionRoleKey(r.MessageID, emoji)]
+	rr.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	mem := rr.client.Cache.GetMember(binding.GuildID, r.UserID)
+	if mem == nil || mem.User.Bot() {
+		return nil, nil
+	}
+	return binding, mem
+}
+
+func (rr *ReactionRoles) handleAdd(_ *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	binding, mem := rr.lookup(r.MessageReaction)
+	if binding == nil {
+		return
+	}
+
+	var err error
+	switch binding.Mode {
+	case ReactionRoleUnique:
+		for _, other := range rr.Bindings(binding.MessageID) {
+			if other.Mode != ReactionRoleUnique || other.key() == binding.key() {
+				continue
+			}
+			// Only the roles the member holds can have a reaction left to remove.
+			if mem.HasRole(other.RoleID) {
+				if err := mem.RemoveRole(other.RoleID); err != nil {
+					rr.client.reportError(err)
+				}
+				rr.client.ses.MessageReactionRemove(other.ChannelID, other.MessageID, other.Emoji, r.UserID)
+			}
+		}
+		err = mem.AddRole(binding.RoleID)
+	case ReactionRoleToggle:
+		if mem.HasRole(binding.RoleID) {
+			err = mem.RemoveRole(binding.RoleID)
+		} else {
+			err = mem.AddRole(binding.RoleID)
+		}
+		rr.client.ses.MessageReactionRemove(binding.ChannelID, binding.MessageID, binding.Emoji, r.UserID)
+	default:
+		if !mem.HasRole(binding.RoleID) {
+			err = mem.AddRole(binding.RoleID)
+		}
+	}
+
+	if err != nil {
+		rr.client.reportError(err)
+	}
+}
+
+func (rr *ReactionRoles) handleRemove(_ *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	binding, mem := rr.lookup(r.MessageReaction)
+	if binding == nil {
+		return
+	}
+
+	switch binding.Mode {
+	case ReactionRoleNormal, ReactionRoleUnique:
+		if err := mem.RemoveRole(binding.RoleID); err != nil {
+			rr.client.reportError(err)
+		}
+	}
+}
+
+// handleMessageDelete drops the bindings of deleted messages.
+func (rr *ReactionRoles) handleMessageDelete(_ *discordgo.Session, m *discordgo.MessageDelete) {
+	for _, r := range rr.Bindings(m.ID) {
+		rr.Lock()
+		delete(rr.bindings, r.key())
+		rr.Unlock()
+
+		if rr.store != nil {
+			if err := rr.store.Delete(r); err != nil {
+				rr.client.reportError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package dgofw
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestFileReactionRoleStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reactionroles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "roles.json")
+	s := NewFileReactionRoleStore(path)
+	if roles, err := s.Load(); err != nil || roles != nil {
+		t.Fatalf("got %v, %v for a missing file, want nothing", roles, err)
+	}
+
+	apple := &ReactionRole{GuildID: "1", ChannelID: "2", MessageID: "5", Emoji: "🍎", RoleID: "20", Mode: ReactionRoleUnique}
+	custom := &ReactionRole{GuildID: "1", ChannelID: "2", MessageID: "5", Emoji: "wave:6", RoleID: "21", Mode: ReactionRoleToggle}
+	for _, r := range []*ReactionRole{apple, custom} {
+		if err = s.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.Delete(custom); err != nil {
+		t.Fatal(err)
+	}
+
+	roles, err := NewFileReactionRoleStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || !reflect.DeepEqual(roles[0], apple) {
+		t.Errorf("got %+v, want only %+v", roles, apple)
+	}
+
+	// The file is replaced, so no temporary files are left behind.
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "roles.json" {
+		t.Errorf("got %d files in the directory, want only the store", len(files))
+	}
+
+	// A failed write leaves the file as it was.
+	if err = os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0700)
+	if os.Getuid() != 0 {
+		if err = s.Save(custom); err == nil {
+			t.Error("saved into a read only directory")
+		}
+		if roles, _ = NewFileReactionRoleStore(path).Load(); len(roles) != 1 {
+			t.Errorf("got %d roles after a failed write, want 1", len(roles))
+		}
+	}
+}
+
+func newReactionRolesClient(t *testing.T) (*DiscordClient, *ReactionRoles, *reasonTransport) {
+	c, _ := newMessageClient()
+	transport := new(reasonTransport)
+	c.ses.Client = &http.Client{Transport: transport}
+
+	g := testGuild("1")
+	g.Roles = []*discordgo.Role{{ID: "1"}, {ID: "20", Position: 1}, {ID: "21", Position: 2}, {ID: "22", Position: 3}, {ID: "23", Position: 4}}
+	c.Cache.UpdateGuild(g)
+
+	rr, err := c.NewReactionRoles(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDiscordMessage(c, testMessage())
+	for _, b := range []struct {
+		emoji, role string
+		mode        ReactionRoleMode
+	}{
+		{"🍎", "20", ReactionRoleUnique},
+		{"🍌", "21", ReactionRoleUnique},
+		{"✅", "22", ReactionRoleVerify},
+		{"<:loop:7>", "23", ReactionRoleToggle},
+	} {
+		if err = rr.Bind(m, b.emoji, b.role, b.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c, rr, transport
+}
+
+func TestAPIEmoji(t *testing.T) {
+	for in, want := range map[string]string{
+		"🍎":          "🍎",
+		"wave:6":     "wave:6",
+		"<:wave:6>":  "wave:6",
+		"<a:wave:6>": "wave:6",
+	} {
+		if got := apiEmoji(in); got != want {
+			t.Errorf("%s: got %s, want %s", in, got, want)
+		}
+	}
+}
+
+func reaction(user, emoji string) *discordgo.MessageReaction {
+	r := &discordgo.MessageReaction{UserID: user, MessageID: "5", ChannelID: "2", GuildID: "1"}
+	if i := strings.IndexByte(emoji, ':'); i >= 0 {
+		r.Emoji = discordgo.Emoji{Name: emoji[:i], ID: emoji[i+1:]}
+	} else {
+		r.Emoji = discordgo.Emoji{Name: emoji}
+	}
+	return r
+}
+
+func TestReactionRoleModes(t *testing.T) {
+	c, rr, transport := newReactionRolesClient(t)
+	mem := c.Cache.GetMember("1", "3")
+	add := func(emoji string) {
+		rr.handleAdd(c.ses, &discordgo.MessageReactionAdd{MessageReaction: reaction("3", emoji)})
+	}
+	remove := func(emoji string) {
+		rr.handleRemove(c.ses, &discordgo.MessageReactionRemove{MessageReaction: reaction("3", emoji)})
+	}
+	expect := func(step string, roles ...string) {
+		t.Helper()
+		if got := mem.RoleIDs(); strings.Join(got, ",") != strings.Join(roles, ",") {
+			t.Errorf("%s: got roles %v, want %v", step, got, roles)
+		}
+	}
+	requests := func() []string {
+		transport.Lock()
+		defer transport.Unlock()
+		result := transport.requests
+		transport.requests = nil
+		return result
+	}
+	requests()
+
+	add("🍎")
+	expect("unique", "20")
+	requests()
+
+	// Picking another unique role swaps them, and removes the old reaction.
+	add("🍌")
+	expect("other unique", "21")
+	got := strings.Join(requests(), "\n")
+	if !strings.Contains(got, "DELETE /api/v9/guilds/1/members/3/roles/20") ||
+		!strings.Contains(got, "/reactions/"+"%F0%9F%8D%8E/3") {
+		t.Errorf("got requests\n%s\nwant the old role and reaction removed", got)
+	}
+
+	remove("🍌")
+	expect("unique removed")
+	requests()
+
+	// With no unique role held, nothing else is removed.
+	add("🍎")
+	if got := requests(); len(got) != 1 {
+		t.Errorf("got requests %q, want only the role added", got)
+	}
+
+	remove("🍎")
+	add("✅")
+	remove("✅")
+	expect("verify kept", "22")
+
+	add("loop:7")
+	expect("toggled on", "22", "23")
+	add("loop:7")
+	expect("toggled off", "22")
+	remove("loop:7")
+	expect("toggle reaction removed", "22")
+
+	toggles := 0
+	for _, r := range requests() {
+		if strings.HasPrefix(r, "DELETE /api/v9/channels/2/messages/5/reactions/loop:7/3") {
+			toggles++
+		}
+	}
+	if toggles != 2 {
+		t.Errorf("removed the toggle reaction %d times, want 2", toggles)
+	}
+
+	// The bot's own reactions are ignored.
+	rr.handleAdd(c.ses, &discordgo.MessageReactionAdd{MessageReaction: reaction("bot", "🍎")})
+	if got := requests(); len(got) != 0 {
+		t.Errorf("got requests %q for the bot's reaction", got)
+	}
+}
+
+func TestReactionRolesMessageDelete(t *testing.T) {
+	c, rr, _ := newReactionRolesClient(t)
+	rr.handleMessageDelete(c.ses, &discordgo.MessageDelete{Message: &discordgo.Message{ID: "5"}})
+	if n := len(rr.Bindings("5")); n != 0 {
+		t.Errorf("got %d bindings on a deleted message", n)
+	}
+}
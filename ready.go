@@ -0,0 +1,194 @@
+package dgofw
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// GuildStreamTimeout is how long to wait for the guilds listed in ``READY``
+// before the cache is considered ready anyway.
+var GuildStreamTimeout = 30 * time.Second
+
+const (
+	eventGuildAvailable = "GUILD_AVAILABLE"
+	eventGuildJoin      = "GUILD_JOIN"
+	eventCacheReady     = "CACHE_READY"
+)
+
+// guildStream tracks which guilds are still expected to stream in after ``READY``.
+//
+// discordgo runs every handler in its own goroutine, so a ``GUILD_CREATE``
+// may be handled before the ``READY`` it follows. Those guilds are held in
+// ``early`` until ``handleReady`` knows which of them it was waiting for.
+type guildStream struct {
+	sync.Mutex
+	session     string // session ID of the last handled READY
+	pending     map[string]struct{}
+	unavailable map[string]struct{}
+	early       []*DiscordGuild
+	ready       bool
+	timer       *time.Timer
+}
+
+// readySession returns the session ID of the last ``READY`` discordgo received.
+// discordgo stores it before any handler runs.
+func readySession(s *discordgo.Session) string {
+	s.State.RLock()
+	defer s.State.RUnlock()
+	return s.State.Ready.SessionID
+}
+
+func (c *DiscordClient) handleReady(s *discordgo.Session, r *discordgo.Ready) {
+	if r.User != nil {
+		c.Cache.UpdateUser(r.User)
+	}
+
+	for _, ch := range r.PrivateChannels {
+		c.Cache.UpdateChannel(ch)
+	}
+
+	st := &c.stream
+	st.Lock()
+	st.session = r.SessionID
+	st.pending = make(map[string]struct{})
+	if st.unavailable == nil {
+		st.unavailable = make(map[string]struct{})
+	}
+	for _, g := range r.Guilds {
+		st.pending[g.ID] = struct{}{}
+		st.unavailable[g.ID] = struct{}{}
+	}
+
+	// Sort out the guilds that were handled before this.
+	var available, joined []*DiscordGuild
+	for _, g := range st.early {
+		if _, ok := st.unavailable[g.ID()]; ok {
+			delete(st.unavailable, g.ID())
+			delete(st.pending, g.ID())
+			available = append(available, g)
+		} else {
+			joined = append(joined, g)
+		}
+	}
+	st.early = nil
+
+	st.ready = false
+	if st.timer != nil {
+		st.timer.Stop()
+	}
+	st.timer = time.AfterFunc(GuildStreamTimeout, c.cacheReady)
+	empty := len(st.pending) == 0
+	st.Unlock()
+
+	for _, g := range available {
+		c.bus.emit(eventGuildAvailable, g)
+	}
+	for _, g := range joined {
+		c.bus.emit(eventGuildJoin, g)
+	}
+	if empty {
+		c.cacheReady()
+	}
+}
+
+// cacheReady emits ``OnCacheReady`` once per ``READY``.
+func (c *DiscordClient) cacheReady() {
+	st := &c.stream
+	st.Lock()
+	if st.ready {
+		st.Unlock()
+		return
+	}
+	st.ready = true
+	if st.timer != nil {
+		st.timer.Stop()
+	}
+	st.Unlock()
+
+	c.bus.emit(eventCacheReady, nil)
+}
+
+// hydrateGuild stores a guild and everything that came with it in the cache.
+//
+// The members and channels don't carry their guild, it is set on copies,
+// since the session state holds the same objects.
+func (c *DiscordClient) hydrateGuild(g *discordgo.Guild) *DiscordGuild {
+	for _, m := range g.Members {
+		mem := *m
+		mem.GuildID = g.ID
+		c.Cache.UpdateMember(&mem)
+	}
+
+	for _, p := range g.Presences {
+		if p.User != nil {
+			c.Cache.UpdateUser(p.User)
+		}
+	}
+
+	result := c.Cache.UpdateGuild(g)
+
+	for _, ch := range append(append([]*discordgo.Channel(nil), g.Channels...), g.Threads...) {
+		raw := *ch
+		raw.GuildID = g.ID
+		c.Cache.UpdateChannel(&raw)
+	}
+	return result
+}
+
+func (c *DiscordClient) handleGuildC(s *discordgo.Session, g *discordgo.GuildCreate) {
+	if g.Unavailable {
+		return
+	}
+
+	guild := c.hydrateGuild(g.Guild)
+
+	st := &c.stream
+	st.Lock()
+	if st.session != readySession(s) {
+		// The READY before this hasn't been handled yet.
+		st.early = append(st.early, guild)
+		st.Unlock()
+		return
+	}
+	_, known := st.unavailable[g.ID]
+	delete(st.unavailable, g.ID)
+	_, streaming := st.pending[g.ID]
+	delete(st.pending, g.ID)
+	done := streaming && len(st.pending) == 0
+	st.Unlock()
+
+	if known {
+		c.bus.emit(eventGuildAvailable, guild)
+	} else {
+		c.bus.emit(eventGuildJoin, guild)
+	}
+
+	if done {
+		c.cacheReady()
+	}
+}
+
+// OnGuildAvailable handles a ``GUILD_CREATE`` event for a guild the bot was already in,
+// either while streaming in guilds after ``READY``, or after an outage.
+func (c *DiscordClient) OnGuildAvailable(once bool, cb func(*DiscordGuild)) {
+	c.bus.add(eventGuildAvailable, once, func(v interface{}) {
+		cb(v.(*DiscordGuild))
+	})
+}
+
+// OnGuildJoin handles a ``GUILD_CREATE`` event for a guild the bot just joined.
+func (c *DiscordClient) OnGuildJoin(once bool, cb func(*DiscordGuild)) {
+	c.bus.add(eventGuildJoin, once, func(v interface{}) {
+		cb(v.(*DiscordGuild))
+	})
+}
+
+// OnCacheReady is called once all guilds listed in ``READY`` have streamed in,
+// or ``GuildStreamTimeout`` has passed.
+func (c *DiscordClient) OnCacheReady(once bool, cb func()) {
+	c.bus.add(eventCacheReady, once, func(interface{}) {
+		cb()
+	})
+}
@@ -0,0 +1,86 @@
+package dgofw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func testReady(c *DiscordClient, session string, guilds ...string) *discordgo.Ready {
+	r := &discordgo.Ready{SessionID: session}
+	for _, id := range guilds {
+		r.Guilds = append(r.Guilds, &discordgo.Guild{ID: id, Unavailable: true})
+	}
+
+	// discordgo stores READY before running any handler.
+	c.ses.State.Lock()
+	c.ses.State.Ready = *r
+	c.ses.State.Unlock()
+	return r
+}
+
+func waitEvent(t *testing.T, ch chan string, want string) {
+	select {
+	case got := <-ch:
+		if got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", want)
+	}
+}
+
+func TestGuildCreateBeforeReady(t *testing.T) {
+	c, _ := newTestClient()
+	events := make(chan string, 10)
+	c.OnGuildAvailable(false, func(g *DiscordGuild) { events <- "available " + g.ID() })
+	c.OnGuildJoin(false, func(g *DiscordGuild) { events <- "join " + g.ID() })
+	c.OnCacheReady(false, func() { events <- "ready" })
+
+	r := testReady(c, "a", "1")
+	c.handleGuildC(c.ses, &discordgo.GuildCreate{Guild: testGuild("1")})
+	select {
+	case e := <-events:
+		t.Fatalf("got %s before READY was handled", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	c.handleReady(c.ses, r)
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-events:
+			got[e] = true
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+	if !got["available 1"] || !got["ready"] {
+		t.Errorf("got %v, want guild 1 available and the cache ready", got)
+	}
+
+	c.handleGuildC(c.ses, &discordgo.GuildCreate{Guild: testGuild("2")})
+	waitEvent(t, events, "join 2")
+}
+
+func TestHydrateGuildCopies(t *testing.T) {
+	c, _ := newTestClient()
+	g := testGuild("1")
+	g.Members = []*discordgo.Member{{User: &discordgo.User{ID: "3", Username: "user 3"}}}
+	g.Channels = []*discordgo.Channel{{ID: "2", Name: "general"}}
+	g.Threads = []*discordgo.Channel{{ID: "4", ParentID: "2", Type: discordgo.ChannelTypeGuildPublicThread}}
+	c.hydrateGuild(g)
+
+	if g.Members[0].GuildID != "" || g.Channels[0].GuildID != "" || g.Threads[0].GuildID != "" {
+		t.Error("the guild of the event was changed")
+	}
+	if m, ok := c.Cache.cachedMember("1", "3"); !ok || m.GuildID() != "1" {
+		t.Error("member not cached in its guild")
+	}
+	for _, id := range []string{"2", "4"} {
+		if ch, ok := c.Cache.cachedChannel(id); !ok || ch.GuildID() != "1" {
+			t.Errorf("channel %s not cached in its guild", id)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package dgofw
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisBackend is a CacheBackend speaking the Redis protocol.
+//
+// It uses a single connection, which is re-established after errors.
+type RedisBackend struct {
+	sync.Mutex
+	dial func() (net.Conn, error)
+	conn net.Conn
+	r    *bufio.Reader
+
+	// Prefix is prepended to all keys. Defaults to ``dgofw:``.
+	Prefix string
+
+	// Password is sent with ``AUTH`` when connecting, if set.
+	Password string
+
+	// DB is selected when connecting, if not 0.
+	DB int
+
+	// TTL expires stored objects, if set.
+	TTL time.Duration
+}
+
+// NewRedisBackend creates a backend for the Redis server at ``addr``.
+func NewRedisBackend(addr string) *RedisBackend {
+	return NewRedisBackendDialer(func() (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, 10*time.Second)
+	})
+}
+
+// NewRedisBackendDialer creates a backend connecting through ``dial``,
+// e.g. to use TLS or an in-process server.
+func NewRedisBackendDialer(dial func() (net.Conn, error)) *RedisBackend {
+	return &RedisBackend{
+		dial:   dial,
+		Prefix: "dgofw:",
+	}
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func (b *RedisBackend) key(kind CacheKind, key string) string {
+	return b.Prefix + kind.String() + ":" + key
+}
+
+func (b *RedisBackend) connect() error {
+	if b.conn != nil {
+		return nil
+	}
+
+	conn, err := b.dial()
+	if err != nil {
+		return err
+	}
+	b.conn, b.r = conn, bufio.NewReader(conn)
+
+	if b.Password != "" {
+		if _, err = b.roundTrip("AUTH", b.Password); err != nil {
+			b.reset()
+			return err
+		}
+	}
+
+	if b.DB != 0 {
+		if _, err = b.roundTrip("SELECT", strconv.Itoa(b.DB)); err != nil {
+			b.reset()
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *RedisBackend) reset() {
+	if b.conn != nil {
+		b.conn.Close()
+	}
+	b.conn, b.r = nil, nil
+}
+
+// do runs a command, reconnecting first if needed.
+func (b *RedisBackend) do(args ...string) (interface{}, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+
+	res, err := b.roundTrip(args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		// The connection is in an unknown state.
+		b.reset()
+	}
+	return res, err
+}
+
+func (b *RedisBackend) roundTrip(args ...string) (interface{}, error) {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+
+	if _, err := b.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return readReply(b.r)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return "", errors.New("redis: malformed reply")
+	}
+	return line[:len(line)-2], nil
+}
+
+// readReply reads one reply. Bulk strings are returned as []byte, nil bulk strings as nil.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		result := make([]interface{}, n)
+		for i := range result {
+			if result[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+}
+
+func (b *RedisBackend) Load(kind CacheKind, key string, v interface{}) (bool, error) {
+	res, err := b.do("GET", b.key(kind, key))
+	if err != nil || res == nil {
+		return false, err
+	}
+
+	raw, ok := res.([]byte)
+	if !ok {
+		return false, fmt.Errorf("redis: unexpected reply %T", res)
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func (b *RedisBackend) Store(kind CacheKind, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if b.TTL > 0 {
+		_, err = b.do("SET", b.key(kind, key), string(raw), "PX", strconv.FormatInt(int64(b.TTL/time.Millisecond), 10))
+	} else {
+		_, err = b.do("SET", b.key(kind, key), string(raw))
+	}
+	return err
+}
+
+func (b *RedisBackend) Delete(kind CacheKind, key string) error {
+	_, err := b.do("DEL", b.key(kind, key))
+	return err
+}
+
+func (b *RedisBackend) Keys(kind CacheKind) ([]string, error) {
+	prefix := b.key(kind, "")
+	result := make([]string, 0)
+	cursor := "0"
+	for {
+		res, err := b.do("SCAN", cursor, "MATCH", prefix+"*", "COUNT", "1000")
+		if err != nil {
+			return nil, err
+		}
+
+		reply, ok := res.([]interface{})
+		if !ok || len(reply) != 2 {
+			return nil, errors.New("redis: malformed SCAN reply")
+		}
+
+		next, _ := reply[0].([]byte)
+		keys, _ := reply[1].([]interface{})
+		for _, k := range keys {
+			if k, ok := k.([]byte); ok {
+				result = append(result, string(k[len(prefix):]))
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return result, nil
+		}
+	}
+}
+
+func (b *RedisBackend) Close() error {
+	b.Lock()
+	defer b.Unlock()
+	b.reset()
+	return nil
+}
@@ -0,0 +1,279 @@
+package dgofw
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeRedis is an in-process stand-in for a Redis server, knowing just
+// the commands RedisBackend uses.
+type fakeRedis struct {
+	sync.Mutex
+	data     map[string]string
+	ttls     map[string]time.Duration
+	password string
+	dials    int
+	conns    []net.Conn
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		data: make(map[string]string),
+		ttls: make(map[string]time.Duration),
+	}
+}
+
+func (s *fakeRedis) dial() (net.Conn, error) {
+	client, server := net.Pipe()
+	s.Lock()
+	s.dials++
+	s.conns = append(s.conns, server)
+	s.Unlock()
+
+	go s.serve(server)
+	return client, nil
+}
+
+// drop closes all connections, as if the server restarted.
+func (s *fakeRedis) drop() {
+	s.Lock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+	s.Unlock()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+
+	for {
+		req, err := readReply(r)
+		if err != nil {
+			return
+		}
+		parts, _ := req.([]interface{})
+		args := make([]string, len(parts))
+		for i, p := range parts {
+			b, _ := p.([]byte)
+			args[i] = string(b)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+			continue
+		}
+
+		var reply string
+		s.Lock()
+		switch cmd {
+		case "AUTH":
+			if args[1] == s.password {
+				authed = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case "SELECT":
+			reply = "+OK\r\n"
+		case "GET":
+			if v, ok := s.data[args[1]]; ok {
+				reply = bulk(v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "SET":
+			s.data[args[1]] = args[2]
+			delete(s.ttls, args[1])
+			if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				s.ttls[args[1]] = time.Duration(ms) * time.Millisecond
+			}
+			reply = "+OK\r\n"
+		case "DEL":
+			n := 0
+			if _, ok := s.data[args[1]]; ok {
+				n = 1
+			}
+			delete(s.data, args[1])
+			reply = fmt.Sprintf(":%d\r\n", n)
+		case "SCAN":
+			reply = s.scan(args[1], strings.TrimSuffix(args[3], "*"))
+		default:
+			reply = "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+		s.Unlock()
+
+		if _, err = conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// scan returns two matching keys per call, so the backend has to page.
+func (s *fakeRedis) scan(cursor, prefix string) string {
+	keys := make([]string, 0)
+	for k := range s.data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	start, _ := strconv.Atoi(cursor)
+	end := start + 2
+	next := strconv.Itoa(end)
+	if end >= len(keys) {
+		end, next = len(keys), "0"
+	}
+
+	page := keys[start:end]
+	reply := "*2\r\n" + bulk(next) + "*" + strconv.Itoa(len(page)) + "\r\n"
+	for _, k := range page {
+		reply += bulk(k)
+	}
+	return reply
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func TestRedisBackend(t *testing.T) {
+	srv := newFakeRedis()
+	b := NewRedisBackendDialer(srv.dial)
+	defer b.Close()
+
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		if err := b.Store(CacheUsers, id, &discordgo.User{ID: id, Username: "user " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Store(CacheGuilds, "1", &discordgo.Guild{ID: "1", Name: "guild"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var u *discordgo.User
+	if ok, err := b.Load(CacheUsers, "3", &u); err != nil || !ok {
+		t.Fatalf("load user 3: %v, %v", ok, err)
+	}
+	if u.ID != "3" || u.Username != "user 3" {
+		t.Errorf("got user %+v", u)
+	}
+
+	var g *discordgo.Guild
+	if ok, err := b.Load(CacheGuilds, "2", &g); err != nil || ok {
+		t.Errorf("load missing guild: %v, %v", ok, err)
+	}
+
+	if err := b.Delete(CacheUsers, "3"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.Load(CacheUsers, "3", &u); ok {
+		t.Error("user 3 wasn't deleted")
+	}
+
+	keys, err := b.Keys(CacheUsers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	if got, want := strings.Join(keys, ","), "1,2,4,5"; got != want {
+		t.Errorf("got keys %s, want %s", got, want)
+	}
+
+	if _, ok := srv.data["dgofw:users:1"]; !ok {
+		t.Error("keys aren't prefixed")
+	}
+}
+
+func TestRedisBackendTTL(t *testing.T) {
+	srv := newFakeRedis()
+	b := NewRedisBackendDialer(srv.dial)
+	b.TTL = time.Minute
+	defer b.Close()
+
+	if err := b.Store(CacheUsers, "1", &discordgo.User{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := srv.ttls["dgofw:users:1"]; ttl != time.Minute {
+		t.Errorf("got TTL %s, want %s", ttl, time.Minute)
+	}
+}
+
+func TestRedisBackendAuth(t *testing.T) {
+	srv := newFakeRedis()
+	srv.password = "secret"
+
+	b := NewRedisBackendDialer(srv.dial)
+	b.Password = "wrong"
+	if err := b.Store(CacheUsers, "1", &discordgo.User{ID: "1"}); err == nil {
+		t.Error("stored with a wrong password")
+	} else if _, ok := err.(redisError); !ok {
+		t.Errorf("got %T, want a redis error", err)
+	}
+
+	b.Password = "secret"
+	b.DB = 2
+	if err := b.Store(CacheUsers, "1", &discordgo.User{ID: "1"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedisBackendReconnect(t *testing.T) {
+	srv := newFakeRedis()
+	b := NewRedisBackendDialer(srv.dial)
+	defer b.Close()
+
+	if err := b.Store(CacheUsers, "1", &discordgo.User{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	srv.drop()
+
+	// The first command notices the broken connection, the next one reconnects.
+	var u *discordgo.User
+	if _, err := b.Load(CacheUsers, "1", &u); err == nil {
+		t.Fatal("used a closed connection")
+	}
+	if ok, err := b.Load(CacheUsers, "1", &u); err != nil || !ok {
+		t.Fatalf("after reconnecting: %v, %v", ok, err)
+	}
+	if srv.dials != 2 {
+		t.Errorf("dialed %d times, want 2", srv.dials)
+	}
+}
+
+func TestRedisBackendCache(t *testing.T) {
+	srv := newFakeRedis()
+	b := NewRedisBackendDialer(srv.dial)
+	defer b.Close()
+
+	// One process fills the cache, another one finds it in Redis.
+	c, _ := newTestClient()
+	c.Cache.SetBackend(b)
+	c.Cache.UpdateGuild(testGuild("1"))
+
+	c2, requests := newTestClient()
+	c2.Cache.SetBackend(NewRedisBackendDialer(srv.dial))
+	if g := c2.Cache.GetGuild("1"); g == nil || g.Name() != "guild 1" {
+		t.Fatal("guild 1 not shared through redis")
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d requests, want 0", n)
+	}
+}
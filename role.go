@@ -0,0 +1,379 @@
+package dgofw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type DiscordRole struct {
+	sync.RWMutex
+	client *DiscordClient
+	guild  string
+	r      *discordgo.Role
+}
+
+func NewDiscordRole(client *DiscordClient, guild string, r *discordgo.Role) *DiscordRole {
+	return &DiscordRole{
+		client: client,
+		guild:  guild,
+		r:      r,
+	}
+}
+
+func (r *DiscordRole) raw() *discordgo.Role {
+	r.RLock()
+	defer r.RUnlock()
+	return r.r
+}
+
+func (r *DiscordRole) set(raw *discordgo.Role) {
+	r.Lock()
+	r.r = raw
+	r.Unlock()
+}
+
+func (r *DiscordRole) ID() string {
+	return r.raw().ID
+}
+
+func (r *DiscordRole) Name() string {
+	return r.raw().Name
+}
+
+func (r *DiscordRole) Color() int {
+	return r.raw().Color
+}
+
+// Position is the position of the role in the hierarchy, higher is more powerful.
+func (r *DiscordRole) Position() int {
+	return r.raw().Position
+}
+
+func (r *DiscordRole) Permissions() Permissions {
+	return Permissions(r.raw().Permissions)
+}
+
+func (r *DiscordRole) Mentionable() bool {
+	return r.raw().Mentionable
+}
+
+// Hoist reports whether members of the role are listed separately.
+func (r *DiscordRole) Hoist() bool {
+	return r.raw().Hoist
+}
+
+// Managed reports whether the role is managed by an integration.
+func (r *DiscordRole) Managed() bool {
+	return r.raw().Managed
+}
+
+func (r *DiscordRole) Mention() string {
+	return fmt.Sprintf("<@&%s>", r.ID())
+}
+
+// IsEveryone reports whether this is the ``@everyone`` role, which shares its ID with the guild.
+func (r *DiscordRole) IsEveryone() bool {
+	return r.ID() == r.guild
+}
+
+func (r *DiscordRole) GuildID() string {
+	return r.guild
+}
+
+func (r *DiscordRole) Guild() *DiscordGuild {
+	return r.client.Cache.GetGuild(r.guild)
+}
+
+func (r *DiscordRole) Client() *DiscordClient {
+	return r.client
+}
+
+// roleParams holds all settings of a role.
+func roleParams(name string, color int, hoist bool, perm Permissions, mention bool) *discordgo.RoleParams {
+	p := int64(perm)
+	return &discordgo.RoleParams{
+		Name:        name,
+		Color:       &color,
+		Hoist:       &hoist,
+		Permissions: &p,
+		Mentionable: &mention,
+	}
+}
+
+// Edit changes all settings of the role at once.
+func (r *DiscordRole) Edit(name string, color int, hoist bool, perm Permissions, mention bool) error {
+	res, err := r.client.ses.GuildRoleEdit(r.guild, r.ID(), roleParams(name, color, hoist, perm, mention))
+	if err != nil {
+		return err
+	}
+	r.client.Cache.updateRoles(r.guild, putRole(res))
+	r.set(res)
+	return nil
+}
+
+func (r *DiscordRole) SetName(name string) error {
+	return r.Edit(name, r.Color(), r.Hoist(), r.Permissions(), r.Mentionable())
+}
+
+func (r *DiscordRole) SetColor(color int) error {
+	return r.Edit(r.Name(), color, r.Hoist(), r.Permissions(), r.Mentionable())
+}
+
+func (r *DiscordRole) SetPermissions(perm Permissions) error {
+	return r.Edit(r.Name(), r.Color(), r.Hoist(), perm, r.Mentionable())
+}
+
+func (r *DiscordRole) Delete() error {
+	if err := r.client.ses.GuildRoleDelete(r.guild, r.ID()); err != nil {
+		return err
+	}
+	r.client.Cache.updateRoles(r.guild, removeRole(r.ID()))
+	return nil
+}
+
+// Move moves the role to ``position``, shifting the other roles.
+func (r *DiscordRole) Move(position int) error {
+	g := r.Guild()
+	if g == nil {
+		return fmt.Errorf("guild %s not found", r.guild)
+	}
+
+	roles := g.Roles()
+	order := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role.ID() != r.ID() && !role.IsEveryone() {
+			order = append(order, role.ID())
+		}
+	}
+
+	// Position 0 is always @everyone.
+	i := position - 1
+	if i < 0 {
+		i = 0
+	} else if i > len(order) {
+		i = len(order)
+	}
+	order = append(order[:i], append([]string{r.ID()}, order[i:]...)...)
+	return g.ReorderRoles(order...)
+}
+
+// Roles returns the roles of the guild, from the lowest position to the highest.
+func (g *DiscordGuild) Roles() []*DiscordRole {
+	iter := g.raw().Roles
+	result := make([]*DiscordRole, len(iter))
+	for i, r := range iter {
+		result[i] = NewDiscordRole(g.client, g.ID(), r)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Position() < result[j].Position()
+	})
+	return result
+}
+
+func (g *DiscordGuild) Role(id string) *DiscordRole {
+	for _, r := range g.raw().Roles {
+		if r.ID == id {
+			return NewDiscordRole(g.client, g.ID(), r)
+		}
+	}
+	return nil
+}
+
+// RoleByName finds a role by its name, ignoring case.
+func (g *DiscordGuild) RoleByName(name string) *DiscordRole {
+	for _, r := range g.raw().Roles {
+		if strings.EqualFold(r.Name, name) {
+			return NewDiscordRole(g.client, g.ID(), r)
+		}
+	}
+	return nil
+}
+
+// Everyone returns the ``@everyone`` role of the guild.
+func (g *DiscordGuild) Everyone() *DiscordRole {
+	return g.Role(g.ID())
+}
+
+// CreateRole creates a role with all its settings in one request, so a failure leaves nothing behind.
+func (g *DiscordGuild) CreateRole(name string, color int, hoist bool, perm Permissions, mention bool) (*DiscordRole, error) {
+	r, err := g.client.ses.GuildRoleCreate(g.ID(), roleParams(name, color, hoist, perm, mention))
+	if err != nil {
+		return nil, err
+	}
+
+	g.client.Cache.updateRoles(g.ID(), putRole(r))
+	return NewDiscordRole(g.client, g.ID(), r), nil
+}
+
+func (g *DiscordGuild) DeleteRole(id string) error {
+	return NewDiscordRole(g.client, g.ID(), &discordgo.Role{ID: id}).Delete()
+}
+
+// ReorderRoles sets the positions of roles, the first one given is placed
+// right above ``@everyone``.
+func (g *DiscordGuild) ReorderRoles(ids ...string) error {
+	roles := make([]*discordgo.Role, len(ids))
+	for i, id := range ids {
+		roles[i] = &discordgo.Role{
+			ID:       id,
+			Position: i + 1,
+		}
+	}
+
+	res, err := g.client.ses.GuildRoleReorder(g.ID(), roles)
+	if err != nil {
+		return err
+	}
+
+	// Discord answers with all roles of the guild.
+	g.client.Cache.updateRoles(g.ID(), func([]*discordgo.Role) []*discordgo.Role {
+		return res
+	})
+	return nil
+}
+
+// RoleIDs returns the IDs of the roles of the member, without ``@everyone``.
+func (m *DiscordMember) RoleIDs() []string {
+	return m.raw().Roles
+}
+
+// Roles returns the roles of the member, without ``@everyone``.
+//
+// Roles the guild doesn't know about are skipped.
+func (m *DiscordMember) Roles() []*DiscordRole {
+	g := m.Guild()
+	if g == nil {
+		return nil
+	}
+
+	ids := m.RoleIDs()
+	result := make([]*DiscordRole, 0, len(ids))
+	for _, id := range ids {
+		if r := g.Role(id); r != nil {
+			result = append(result, r)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Position() < result[j].Position()
+	})
+	return result
+}
+
+// TopRole returns the highest role of the member, or ``@everyone``.
+func (m *DiscordMember) TopRole() *DiscordRole {
+	if roles := m.Roles(); len(roles) > 0 {
+		return roles[len(roles)-1]
+	}
+	if g := m.Guild(); g != nil {
+		return g.Everyone()
+	}
+	return nil
+}
+
+func (m *DiscordMember) HasRole(id string) bool {
+	for _, r := range m.RoleIDs() {
+		if r == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *DiscordMember) AddRole(id string) error {
+	if err := m.client.checkBotRole(m.GuildID(), id); err != nil {
+		return err
+	}
+	if err := m.client.ses.GuildMemberRoleAdd(m.GuildID(), m.User.ID(), id); err != nil {
+		return err
+	}
+
+	if !m.HasRole(id) {
+		m.setRoles(append(append([]string(nil), m.RoleIDs()...), id))
+	}
+	return nil
+}
+
+func (m *DiscordMember) RemoveRole(id string) error {
+	if err := m.client.checkBotRole(m.GuildID(), id); err != nil {
+		return err
+	}
+	if err := m.client.ses.GuildMemberRoleRemove(m.GuildID(), m.User.ID(), id); err != nil {
+		return err
+	}
+
+	roles := make([]string, 0)
+	for _, r := range m.RoleIDs() {
+		if r != id {
+			roles = append(roles, r)
+		}
+	}
+	m.setRoles(roles)
+	return nil
+}
+
+// setRoles stores changed roles in the cache, without waiting for the member update event.
+func (m *DiscordMember) setRoles(roles []string) {
+	raw := *m.raw()
+	raw.Roles = roles
+	m.client.Cache.UpdateMember(&raw)
+}
+
+// updateRoles changes the roles of a cached guild, without waiting for the role events.
+// ``fn`` gets a copy of the roles, and returns the new ones.
+func (c *DiscordCache) updateRoles(guild string, fn func([]*discordgo.Role) []*discordgo.Role) {
+	c.RLock()
+	v, ok := c.guilds.peek(guild)
+	c.RUnlock()
+	if !ok {
+		return
+	}
+
+	g := v.(*DiscordGuild)
+	g.Lock()
+	raw := *g.g
+	raw.Roles = fn(append([]*discordgo.Role(nil), raw.Roles...))
+	g.g = &raw
+	g.Unlock()
+	c.persist(CacheGuilds, guild, &raw)
+}
+
+// putRole adds a role, or replaces the role with the same ID.
+func putRole(r *discordgo.Role) func([]*discordgo.Role) []*discordgo.Role {
+	return func(roles []*discordgo.Role) []*discordgo.Role {
+		for i, role := range roles {
+			if role.ID == r.ID {
+				roles[i] = r
+				return roles
+			}
+		}
+		return append(roles, r)
+	}
+}
+
+func removeRole(id string) func([]*discordgo.Role) []*discordgo.Role {
+	return func(roles []*discordgo.Role) []*discordgo.Role {
+		result := roles[:0]
+		for _, role := range roles {
+			if role.ID != id {
+				result = append(result, role)
+			}
+		}
+		return result
+	}
+}
+
+func (c *DiscordClient) handleRoleCreate(_ *discordgo.Session, r *discordgo.GuildRoleCreate) {
+	c.Cache.updateRoles(r.GuildID, putRole(copyRole(r.Role)))
+}
+
+func (c *DiscordClient) handleRoleUpdate(_ *discordgo.Session, r *discordgo.GuildRoleUpdate) {
+	c.Cache.updateRoles(r.GuildID, putRole(copyRole(r.Role)))
+}
+
+func (c *DiscordClient) handleRoleDelete(_ *discordgo.Session, r *discordgo.GuildRoleDelete) {
+	c.Cache.updateRoles(r.GuildID, removeRole(r.RoleID))
+}
@@ -0,0 +1,83 @@
+package dgofw
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRoleEvents(t *testing.T) {
+	c, _ := newTestClient()
+	c.Cache.UpdateGuild(testGuild("1"))
+	g := c.Cache.GetGuild("1")
+
+	c.handleRoleCreate(c.ses, &discordgo.GuildRoleCreate{GuildRole: &discordgo.GuildRole{
+		GuildID: "1",
+		Role:    &discordgo.Role{ID: "2", Name: "mods", Position: 1},
+	}})
+	if r := g.Role("2"); r == nil || r.Name() != "mods" {
+		t.Fatal("created role not cached")
+	}
+
+	c.handleRoleUpdate(c.ses, &discordgo.GuildRoleUpdate{GuildRole: &discordgo.GuildRole{
+		GuildID: "1",
+		Role:    &discordgo.Role{ID: "2", Name: "admins", Position: 1},
+	}})
+	if r := g.Role("2"); r == nil || r.Name() != "admins" {
+		t.Error("updated role not cached")
+	}
+	if n := len(g.Roles()); n != 1 {
+		t.Errorf("got %d roles, want 1", n)
+	}
+
+	c.handleRoleDelete(c.ses, &discordgo.GuildRoleDelete{GuildID: "1", RoleID: "2"})
+	if g.Role("2") != nil {
+		t.Error("deleted role still cached")
+	}
+}
+
+// roleTransport creates roles, and records the requests it gets.
+type roleTransport struct {
+	sync.Mutex
+	requests []string
+}
+
+func (t *roleTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := ioutil.ReadAll(r.Body)
+	t.Lock()
+	t.requests = append(t.requests, r.Method+" "+string(body))
+	t.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"id": "2", "name": "mods", "permissions": "2"}`)),
+		Request:    r,
+	}, nil
+}
+
+func TestCreateRole(t *testing.T) {
+	c, _ := newTestClient()
+	transport := new(roleTransport)
+	c.ses.Client = &http.Client{Transport: transport}
+	c.Cache.UpdateGuild(testGuild("1"))
+	g := c.Cache.GetGuild("1")
+
+	r, err := g.CreateRole("mods", 0, false, PermissionKickMembers, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.requests) != 1 || !strings.HasPrefix(transport.requests[0], `POST {"name":"mods"`) {
+		t.Errorf("made requests %q, want the role created with its settings at once", transport.requests)
+	}
+	if r.Permissions() != PermissionKickMembers {
+		t.Errorf("got permissions %s", r.Permissions())
+	}
+	if g.Role("2") == nil {
+		t.Error("created role not cached")
+	}
+}
@@ -0,0 +1,268 @@
+package dgofw
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MessageLimit is the maximum amount of characters Discord accepts in a single message.
+const MessageLimit = 2000
+
+// SplitOptions controls how long messages are split up when sent.
+type SplitOptions struct {
+	// Limit is the maximum amount of characters per message.
+	// Defaults to ``MessageLimit``.
+	Limit int
+
+	// FileThreshold is the amount of characters above which the content
+	// is uploaded as a text file instead of being split.
+	// Zero disables the file fallback.
+	FileThreshold int
+
+	// FileName is the name of the uploaded file. Defaults to ``message.txt``.
+	FileName string
+}
+
+// DefaultSplitOptions are used when no options are given to a splitting sender.
+var DefaultSplitOptions = SplitOptions{
+	Limit:    MessageLimit,
+	FileName: "message.txt",
+}
+
+func (o *SplitOptions) limit() int {
+	if o == nil || o.Limit <= 0 {
+		return MessageLimit
+	}
+	return o.Limit
+}
+
+func (o *SplitOptions) fileName() string {
+	if o == nil || o.FileName == "" {
+		return DefaultSplitOptions.FileName
+	}
+	return o.FileName
+}
+
+func (o *SplitOptions) asFile(msg string) bool {
+	return o != nil && o.FileThreshold > 0 && utf8.RuneCountInString(msg) > o.FileThreshold
+}
+
+// SplitMessage splits ``content`` into chunks of at most ``limit`` characters.
+//
+// Chunks are broken at newlines where possible, then at spaces, and only
+// as a last resort in the middle of a word. Markdown code blocks that span
+// several chunks are closed at the end of a chunk and re-opened, with the
+// same language, at the start of the next one. Chunks of only whitespace,
+// which Discord rejects, are left out.
+func SplitMessage(content string, limit int) []string {
+	if limit <= 0 {
+		limit = MessageLimit
+	}
+
+	// Code blocks are only re-opened if there is room for the fences.
+	fences := limit > len("```\n")+len("\n```")
+
+	var (
+		result []string
+		open   bool
+		lang   string
+	)
+	add := func(chunk string) {
+		if strings.TrimSpace(chunk) != "" {
+			result = append(result, chunk)
+		}
+	}
+	for len(content) > 0 {
+		prefix := ""
+		if open && fences {
+			prefix = "```" + lang + "\n"
+			// Don't let the language take up the room meant for the content.
+			if utf8.RuneCountInString(prefix)+len("\n```") > limit/2 {
+				prefix = "```\n"
+			}
+		}
+
+		if utf8.RuneCountInString(prefix+content) <= limit {
+			add(prefix + content)
+			break
+		}
+
+		// Always leave room for closing a code block.
+		budget := limit - utf8.RuneCountInString(prefix)
+		if fences {
+			budget -= len("\n```")
+		}
+
+		var chunk string
+		chunk, content = cutChunk(content, budget)
+
+		open, lang = scanFences(chunk, open, lang)
+		chunk = prefix + chunk
+		if open && fences {
+			chunk += "\n```"
+		}
+		add(chunk)
+	}
+	return result
+}
+
+// cutChunk cuts at most ``budget`` characters off the front of ``s``,
+// preferring a line break, then a space.
+func cutChunk(s string, budget int) (chunk, rest string) {
+	end, n := 0, 0
+	for end < len(s) && n < budget {
+		_, size := utf8.DecodeRuneInString(s[end:])
+		end += size
+		n++
+	}
+
+	if end >= len(s) {
+		return s, ""
+	}
+
+	if i := strings.LastIndexByte(s[:end+1], '\n'); i > 0 {
+		return s[:i], s[i+1:]
+	}
+
+	if i := strings.LastIndexByte(s[:end+1], ' '); i > 0 {
+		return s[:i], s[i+1:]
+	}
+
+	// Don't cut a code fence in half.
+	if b := fenceCut(s, end); b > 0 && b < end {
+		end -= b
+	}
+	return s[:end], s[end:]
+}
+
+// fenceCut returns how many backticks before ``end`` belong to a code fence
+// that continues after it, so cutting there would split the fence. It is at most 2.
+func fenceCut(s string, end int) int {
+	before := 0
+	for before < 3 && end-before > 0 && s[end-before-1] == '`' {
+		before++
+	}
+	after := 0
+	for before+after < 3 && end+after < len(s) && s[end+after] == '`' {
+		after++
+	}
+
+	if before == 0 || before == 3 || before+after < 3 {
+		return 0
+	}
+	return before
+}
+
+// maxFenceLang is the longest text after an opening fence that is taken as its language.
+const maxFenceLang = 32
+
+// scanFences walks the code fences in ``s`` starting from the given state,
+// and returns whether a code block is still open at the end, and its language.
+//
+// Only a short word on the rest of the fence's line counts as a language.
+func scanFences(s string, open bool, lang string) (bool, string) {
+	for {
+		i := strings.Index(s, "```")
+		if i < 0 {
+			return open, lang
+		}
+		s = s[i+3:]
+
+		if open {
+			open, lang = false, ""
+			continue
+		}
+
+		open = true
+		if j := strings.IndexByte(s, '\n'); j >= 0 && j <= maxFenceLang && isFenceLang(s[:j]) {
+			lang = s[:j]
+		}
+	}
+}
+
+func isFenceLang(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '-', r == '+', r == '#', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// sendSplit sends ``msg`` in as many messages as needed. If ``ref`` is set,
+// the first message replies to it.
+func (c *DiscordClient) sendSplit(channel, msg string, opts *SplitOptions, ref *DiscordMessage) []*DiscordMessage {
+	newMessage := func() *MessageBuilder {
+		if ref != nil {
+			return ref.NewReply()
+		}
+		return c.NewMessage(channel)
+	}
+
+	if opts.asFile(msg) {
+		m, err := newMessage().File(opts.fileName(), strings.NewReader(msg)).Send()
+		if err != nil {
+			c.reportError(err)
+			return nil
+		}
+		return []*DiscordMessage{m}
+	}
+
+	chunks := SplitMessage(msg, opts.limit())
+	result := make([]*DiscordMessage, 0, len(chunks))
+	for i, chunk := range chunks {
+		b := c.NewMessage(channel)
+		if i == 0 {
+			b = newMessage()
+		}
+
+		m, err := b.Content(chunk).Send()
+		if err != nil {
+			c.reportError(err)
+			break
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// SendSplit sends ``msg`` to a channel, split into as many messages as needed.
+//
+// ``opts`` may be nil, in which case ``DefaultSplitOptions`` are used.
+func (c *DiscordClient) SendSplit(channel, msg string, opts *SplitOptions) []*DiscordMessage {
+	if opts == nil {
+		opts = &DefaultSplitOptions
+	}
+	return c.sendSplit(channel, msg, opts, nil)
+}
+
+// SendSplit sends ``msg`` to the channel, split into as many messages as needed.
+func (c *DiscordChannel) SendSplit(msg string, opts *SplitOptions) []*DiscordMessage {
+	return c.client.SendSplit(c.ID(), msg, opts)
+}
+
+// ReplySplit replies with ``msg``, split into as many messages as needed.
+// Only the first message references the one replied to.
+//
+// If the content is longer than ``opts.FileThreshold`` it is uploaded
+// as a file instead.
+func (m *DiscordMessage) ReplySplit(msg string, opts *SplitOptions) []*DiscordMessage {
+	if opts == nil {
+		opts = &DefaultSplitOptions
+	}
+	return m.client.sendSplit(m.ChannelID(), msg, opts, m)
+}
+
+// last returns the last message of a split send, or nil.
+func last(msgs []*DiscordMessage) *DiscordMessage {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return msgs[len(msgs)-1]
+}
@@ -0,0 +1,164 @@
+package dgofw
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    []string
+	}{
+		{
+			name:    "short",
+			content: "hello",
+			limit:   10,
+			want:    []string{"hello"},
+		},
+		{
+			name:    "at newlines",
+			content: "first line\nsecond line",
+			limit:   15,
+			want:    []string{"first line", "second line"},
+		},
+		{
+			name:    "at spaces",
+			content: "aaaa bbbb cccc",
+			limit:   13,
+			want:    []string{"aaaa bbbb", "cccc"},
+		},
+		{
+			name:    "in a word",
+			content: "aaaaaaaaaaaa",
+			limit:   5,
+			want:    []string{"aaaaa", "aaaaa", "aa"},
+		},
+		{
+			name:    "code block re-opened with its language",
+			content: "```go\nline one\nline two\n```",
+			limit:   20,
+			want:    []string{"```go\nline one\n```", "```go\nline two\n```"},
+		},
+		{
+			name:    "only whitespace",
+			content: strings.Repeat("\n", 30),
+			limit:   10,
+			want:    nil,
+		},
+		{
+			name:    "whitespace between text",
+			content: "a" + strings.Repeat("\n", 30) + "b",
+			limit:   10,
+			want:    []string{"a\n\n\n\n\n", "\n\n\nb"},
+		},
+		{
+			name:    "no room for fences",
+			content: "```abcdefgh```",
+			limit:   8,
+			want:    []string{"```abcde", "fgh```"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := SplitMessage(tt.content, tt.limit)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMessageLimit(t *testing.T) {
+	inputs := map[string]string{
+		"fence without newline": "```" + strings.Repeat("b", 3000) + "```",
+		"long language":         "```" + strings.Repeat("x", 1990) + "\n" + strings.Repeat("b ", 2000) + "```",
+		"code block":            "```go\n" + strings.Repeat("fmt.Println(\"hello\")\n", 500) + "```",
+		"multibyte":             strings.Repeat("ö", 4500),
+	}
+
+	for name, content := range inputs {
+		parts := SplitMessage(content, MessageLimit)
+		if len(parts) > 10 {
+			t.Errorf("%s: got %d parts", name, len(parts))
+		}
+		for i, p := range parts {
+			if n := utf8.RuneCountInString(p); n > MessageLimit {
+				t.Errorf("%s: part %d has %d characters", name, i, n)
+			}
+		}
+	}
+}
+
+func TestCutChunk(t *testing.T) {
+	tests := []struct {
+		s           string
+		budget      int
+		chunk, rest string
+	}{
+		{"hello", 10, "hello", ""},
+		{"hello world", 8, "hello", "world"},
+		{"ab\ncd ef", 7, "ab", "cd ef"},
+		{"abcdef", 4, "abcd", "ef"},
+		{"öööö", 2, "öö", "öö"},
+		{"ab```cd", 3, "ab", "```cd"},
+		{"ab```cd", 4, "ab", "```cd"},
+	}
+
+	for _, tt := range tests {
+		chunk, rest := cutChunk(tt.s, tt.budget)
+		if chunk != tt.chunk || rest != tt.rest {
+			t.Errorf("cutChunk(%q, %d) = %q, %q, want %q, %q", tt.s, tt.budget, chunk, rest, tt.chunk, tt.rest)
+		}
+	}
+}
+
+func TestFenceCut(t *testing.T) {
+	tests := []struct {
+		s    string
+		end  int
+		want int
+	}{
+		{"abc", 2, 0},
+		{"a```b", 1, 0},
+		{"a```b", 2, 1},
+		{"a```b", 3, 2},
+		{"a```b", 4, 0},
+		{"a``b", 3, 0},
+	}
+
+	for _, tt := range tests {
+		if got := fenceCut(tt.s, tt.end); got != tt.want {
+			t.Errorf("fenceCut(%q, %d) = %d, want %d", tt.s, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestScanFences(t *testing.T) {
+	tests := []struct {
+		s            string
+		open         bool
+		lang         string
+		wantOpen     bool
+		wantLanguage string
+	}{
+		{"no fences", false, "", false, ""},
+		{"```go\ncode", false, "", true, "go"},
+		{"```go\ncode\n```", false, "", false, ""},
+		{"code\n```", true, "go", false, ""},
+		{"code", true, "go", true, "go"},
+		{"```" + strings.Repeat("b", 100), false, "", true, ""},
+		{"```" + strings.Repeat("b", 40) + "\ncode", false, "", true, ""},
+		{"```not a language\ncode", false, "", true, ""},
+		{"```c++\ncode", false, "", true, "c++"},
+	}
+
+	for _, tt := range tests {
+		open, lang := scanFences(tt.s, tt.open, tt.lang)
+		if open != tt.wantOpen || lang != tt.wantLanguage {
+			t.Errorf("scanFences(%q, %v, %q) = %v, %q, want %v, %q", tt.s, tt.open, tt.lang, open, lang, tt.wantOpen, tt.wantLanguage)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package dgofw
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Thread channel types, kept from before discordgo knew about them.
+const (
+	ChannelTypeGuildNewsThread    = discordgo.ChannelTypeGuildNewsThread
+	ChannelTypeGuildPublicThread  = discordgo.ChannelTypeGuildPublicThread
+	ChannelTypeGuildPrivateThread = discordgo.ChannelTypeGuildPrivateThread
+)
+
+const (
+	eventThreadCreate        = "THREAD_CREATE"
+	eventThreadUpdate        = "THREAD_UPDATE"
+	eventThreadDelete        = "THREAD_DELETE"
+	eventThreadMembersUpdate = "THREAD_MEMBERS_UPDATE"
+)
+
+type (
+	// ThreadList is a page of threads, and the thread members of the bot in them.
+	ThreadList struct {
+		Threads []*DiscordChannel
+		Members []*discordgo.ThreadMember
+		HasMore bool
+	}
+
+	// ThreadOptions are the settings of a new thread.
+	ThreadOptions struct {
+		Name string `json:"name"`
+
+		// AutoArchiveDuration is the inactivity in minutes after which the thread
+		// is archived, one of 60, 1440, 4320 or 10080.
+		AutoArchiveDuration int `json:"auto_archive_duration,omitempty"`
+
+		// Type defaults to a public thread. It is ignored for threads started from a message.
+		Type      discordgo.ChannelType `json:"type,omitempty"`
+		Invitable *bool                 `json:"invitable,omitempty"`
+		Slowmode  int                   `json:"rate_limit_per_user,omitempty"`
+		Reason    string                `json:"-"`
+	}
+)
+
+// threadRequest sends a request to the thread API.
+func (c *DiscordClient) threadRequest(method, path string, data interface{}, options ...discordgo.RequestOption) ([]byte, error) {
+	bucket := discordgo.EndpointAPI + path
+	if i := strings.IndexByte(bucket, '?'); i >= 0 {
+		bucket = bucket[:i]
+	}
+	return c.ses.RequestWithBucketID(method, discordgo.EndpointAPI+path, data, bucket, options...)
+}
+
+// decodeThread caches a thread channel.
+func (c *DiscordClient) decodeThread(data []byte) (*DiscordChannel, error) {
+	raw := new(discordgo.Channel)
+	if err := json.Unmarshal(data, raw); err != nil {
+		return nil, err
+	}
+	return c.Cache.UpdateChannel(raw), nil
+}
+
+func (c *DiscordClient) decodeThreadList(data []byte) (*ThreadList, error) {
+	list := new(discordgo.ThreadsList)
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, err
+	}
+
+	result := &ThreadList{
+		Threads: make([]*DiscordChannel, len(list.Threads)),
+		Members: list.Members,
+		HasMore: list.HasMore,
+	}
+	for i, raw := range list.Threads {
+		result.Threads[i] = c.Cache.UpdateChannel(raw)
+	}
+	return result, nil
+}
+
+// IsThread reports whether the channel is a thread. ``Parent`` returns the channel it is in.
+func (c *DiscordChannel) IsThread() bool {
+	switch c.Type() {
+	case ChannelTypeGuildNewsThread, ChannelTypeGuildPublicThread, ChannelTypeGuildPrivateThread:
+		return true
+	}
+	return false
+}
+
+// ThreadMetadata returns the archive and lock state of a thread, or nil if it isn't a thread.
+func (c *DiscordChannel) ThreadMetadata() *discordgo.ThreadMetadata {
+	if m := c.raw().ThreadMetadata; m != nil {
+		result := *m
+		return &result
+	}
+	return nil
+}
+
+// OwnerID is the ID of the user who started the thread.
+func (c *DiscordChannel) OwnerID() string {
+	return c.raw().OwnerID
+}
+
+// MemberCount is an approximate count of the members of the thread, up to 50.
+func (c *DiscordChannel) MemberCount() int {
+	return c.raw().MemberCount
+}
+
+// MessageCount is an approximate count of the messages in the thread, up to 50.
+func (c *DiscordChannel) MessageCount() int {
+	return c.raw().MessageCount
+}
+
+func (c *DiscordChannel) Archived() bool {
+	m := c.raw().ThreadMetadata
+	return m != nil && m.Archived
+}
+
+func (c *DiscordChannel) ThreadLocked() bool {
+	m := c.raw().ThreadMetadata
+	return m != nil && m.Locked
+}
+
+// CreateThread starts a thread in the channel that isn't attached to a message.
+func (c *DiscordChannel) CreateThread(opts ThreadOptions) (*DiscordChannel, error) {
+	if opts.Type == 0 {
+		opts.Type = ChannelTypeGuildPublicThread
+		if c.Type() == ChannelTypeGuildNews {
+			opts.Type = ChannelTypeGuildNewsThread
+		}
+	}
+
+	res, err := c.client.threadRequest("POST", "channels/"+c.ID()+"/threads", opts, auditReason(opts.Reason)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.decodeThread(res)
+}
+
+// StartThread starts a thread from the message.
+func (m *DiscordMessage) StartThread(name string) (*DiscordChannel, error) {
+	return m.StartThreadComplex(ThreadOptions{Name: name})
+}
+
+func (m *DiscordMessage) StartThreadComplex(opts ThreadOptions) (*DiscordChannel, error) {
+	opts.Type = 0
+	path := "channels/" + m.ChannelID() + "/messages/" + m.ID() + "/threads"
+	res, err := m.client.threadRequest("POST", path, opts, auditReason(opts.Reason)...)
+	if err != nil {
+		return nil, err
+	}
+	return m.client.decodeThread(res)
+}
+
+// InThread reports whether the message was sent in a thread.
+func (m *DiscordMessage) InThread() bool {
+	ch := m.Channel()
+	return ch != nil && ch.IsThread()
+}
+
+// editThread patches the thread, and caches the result.
+func (c *DiscordChannel) editThread(data map[string]interface{}, reason string) error {
+	res, err := c.client.threadRequest("PATCH", "channels/"+c.ID(), data, auditReason(reason)...)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.decodeThread(res)
+	return err
+}
+
+func (c *DiscordChannel) Archive(reason string) error {
+	return c.editThread(map[string]interface{}{"archived": true}, reason)
+}
+
+func (c *DiscordChannel) Unarchive(reason string) error {
+	return c.editThread(map[string]interface{}{"archived": false}, reason)
+}
+
+// LockThread stops members without Manage Threads from unarchiving the thread.
+func (c *DiscordChannel) LockThread(reason string) error {
+	return c.editThread(map[string]interface{}{"locked": true}, reason)
+}
+
+func (c *DiscordChannel) UnlockThread(reason string) error {
+	return c.editThread(map[string]interface{}{"locked": false}, reason)
+}
+
+// Join adds the bot to the thread.
+func (c *DiscordChannel) Join() error {
+	return c.AddThreadMember("@me")
+}
+
+// Leave removes the bot from the thread.
+func (c *DiscordChannel) Leave() error {
+	return c.RemoveThreadMember("@me")
+}
+
+func (c *DiscordChannel) AddThreadMember(user string) error {
+	_, err := c.client.threadRequest("PUT", "channels/"+c.ID()+"/thread-members/"+user, nil)
+	return err
+}
+
+func (c *DiscordChannel) RemoveThreadMember(user string) error {
+	_, err := c.client.threadRequest("DELETE", "channels/"+c.ID()+"/thread-members/"+user, nil)
+	return err
+}
+
+// ThreadMembers lists the members of the thread.
+func (c *DiscordChannel) ThreadMembers() ([]*discordgo.ThreadMember, error) {
+	res, err := c.client.threadRequest("GET", "channels/"+c.ID()+"/thread-members", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*discordgo.ThreadMember, 0)
+	if err = json.Unmarshal(res, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ActiveThreads lists the threads in the guild that aren't archived.
+func (g *DiscordGuild) ActiveThreads() (*ThreadList, error) {
+	res, err := g.client.threadRequest("GET", "guilds/"+g.ID()+"/threads/active", nil)
+	if err != nil {
+		return nil, err
+	}
+	return g.client.decodeThreadList(res)
+}
+
+// ActiveThreads lists the threads in the channel that aren't archived.
+func (c *DiscordChannel) ActiveThreads() ([]*DiscordChannel, error) {
+	g := c.Guild()
+	if g == nil {
+		return nil, nil
+	}
+
+	list, err := g.ActiveThreads()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DiscordChannel, 0)
+	for _, t := range list.Threads {
+		if t.ParentID() == c.ID() {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// ArchivedThreads lists archived threads in the channel, most recently archived first.
+// ``before`` is an archive timestamp to page from, and may be empty.
+func (c *DiscordChannel) ArchivedThreads(private bool, before string, limit int) (*ThreadList, error) {
+	kind := "public"
+	if private {
+		kind = "private"
+	}
+
+	v := url.Values{}
+	if before != "" {
+		v.Set("before", before)
+	}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := "channels/" + c.ID() + "/threads/archived/" + kind
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+
+	res, err := c.client.threadRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.decodeThreadList(res)
+}
+
+// handleThreadCreate caches a thread the bot can see, including private threads it was added to.
+func (c *DiscordClient) handleThreadCreate(_ *discordgo.Session, t *discordgo.ThreadCreate) {
+	c.bus.emit(eventThreadCreate, c.Cache.UpdateChannel(t.Channel))
+}
+
+func (c *DiscordClient) handleThreadUpdate(_ *discordgo.Session, t *discordgo.ThreadUpdate) {
+	c.bus.emit(eventThreadUpdate, c.Cache.UpdateChannel(t.Channel))
+}
+
+func (c *DiscordClient) handleThreadDelete(_ *discordgo.Session, t *discordgo.ThreadDelete) {
+	ch, ok := c.Cache.cachedChannel(t.ID)
+	if !ok {
+		ch = NewDiscordChannel(c, t.Channel)
+	}
+	c.Cache.DeleteChannel(t.ID)
+	c.bus.emit(eventThreadDelete, ch)
+}
+
+// handleThreadListSync caches the active threads of channels the bot gained access to.
+func (c *DiscordClient) handleThreadListSync(_ *discordgo.Session, t *discordgo.ThreadListSync) {
+	for _, raw := range t.Threads {
+		c.Cache.UpdateChannel(raw)
+	}
+}
+
+func (c *DiscordClient) handleThreadMembersUpdate(_ *discordgo.Session, t *discordgo.ThreadMembersUpdate) {
+	if ch, ok := c.Cache.cachedChannel(t.ID); ok {
+		raw := *ch.raw()
+		raw.MemberCount = t.MemberCount
+		c.Cache.UpdateChannel(&raw)
+	}
+	c.bus.emit(eventThreadMembersUpdate, t)
+}
+
+// OnThreadCreate handles a ``THREAD_CREATE`` event, also sent when the bot is added to a private thread.
+func (c *DiscordClient) OnThreadCreate(once bool, cb func(*DiscordChannel)) {
+	c.bus.add(eventThreadCreate, once, func(v interface{}) {
+		cb(v.(*DiscordChannel))
+	})
+}
+
+func (c *DiscordClient) OnThreadUpdate(once bool, cb func(*DiscordChannel)) {
+	c.bus.add(eventThreadUpdate, once, func(v interface{}) {
+		cb(v.(*DiscordChannel))
+	})
+}
+
+// OnThreadDelete handles a ``THREAD_DELETE`` event. The thread is only complete if it was cached.
+func (c *DiscordClient) OnThreadDelete(once bool, cb func(*DiscordChannel)) {
+	c.bus.add(eventThreadDelete, once, func(v interface{}) {
+		cb(v.(*DiscordChannel))
+	})
+}
+
+func (c *DiscordClient) OnThreadMembersUpdate(once bool, cb func(*discordgo.ThreadMembersUpdate)) {
+	c.bus.add(eventThreadMembersUpdate, once, func(v interface{}) {
+		cb(v.(*discordgo.ThreadMembersUpdate))
+	})
+}
@@ -0,0 +1,61 @@
+package dgofw
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func testThread(id, parent, guild string) *discordgo.Channel {
+	return &discordgo.Channel{
+		ID:             id,
+		GuildID:        guild,
+		ParentID:       parent,
+		Type:           discordgo.ChannelTypeGuildPublicThread,
+		Name:           "thread " + id,
+		ThreadMetadata: &discordgo.ThreadMetadata{Locked: true},
+	}
+}
+
+func TestThreadEvents(t *testing.T) {
+	c, _ := newMessageClient()
+	created := make(chan string, 1)
+	c.OnThreadCreate(false, func(ch *DiscordChannel) { created <- ch.ID() })
+
+	c.handleThreadCreate(c.ses, &discordgo.ThreadCreate{Channel: testThread("6", "2", "1")})
+	waitEvent(t, created, "6")
+
+	ch := c.Cache.GetChannel("6")
+	if ch == nil || !ch.IsThread() || !ch.ThreadLocked() || ch.Parent().ID() != "2" {
+		t.Fatal("thread not cached with its metadata")
+	}
+
+	c.handleThreadMembersUpdate(c.ses, &discordgo.ThreadMembersUpdate{ID: "6", GuildID: "1", MemberCount: 3})
+	if n := c.Cache.GetChannel("6").MemberCount(); n != 3 {
+		t.Errorf("got %d members, want 3", n)
+	}
+
+	c.handleThreadDelete(c.ses, &discordgo.ThreadDelete{Channel: &discordgo.Channel{ID: "6"}})
+	if _, ok := c.Cache.cachedChannel("6"); ok {
+		t.Error("deleted thread still cached")
+	}
+}
+
+func TestMsgHandlerInThread(t *testing.T) {
+	c, _ := newMessageClient()
+	c.Cache.UpdateChannel(testThread("6", "2", "1"))
+
+	got := make(chan string, 2)
+	c.OnMessage("!ping", false, func(m *DiscordMessage) { got <- "parent " + m.ChannelID() }).In("2")
+	c.OnMessage("!ping", false, func(m *DiscordMessage) { got <- "other " + m.ChannelID() }).In("7")
+
+	raw := testMessage()
+	raw.Content = "!ping"
+	raw.ChannelID = "6"
+	c.handleMessageC(c.ses, &discordgo.MessageCreate{Message: raw})
+	waitEvent(t, got, "parent 6")
+
+	if !NewDiscordMessage(c, raw).InThread() {
+		t.Error("message not in a thread")
+	}
+}
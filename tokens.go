@@ -0,0 +1,78 @@
+package dgofw
+
+import (
+	"github.com/Krognol/dgofw/format"
+	"github.com/bwmarrin/discordgo"
+)
+
+// MessageToken is a mention, emoji or timestamp token resolved against the cache.
+//
+// Depending on the token type one of ``User``, ``Channel``, ``Role`` or ``Emoji`` is set,
+// or none at all if the entity could not be found.
+type MessageToken struct {
+	*format.Token
+	User    *DiscordUser
+	Channel *DiscordChannel
+	Role    *DiscordRole
+	Emoji   *discordgo.Emoji
+}
+
+// Tokens parses and resolves all tokens in the message content.
+func (m *DiscordMessage) Tokens() []*MessageToken {
+	return m.client.ResolveTokens(m.GuildID(), m.Content(), m.Mentions...)
+}
+
+// ResolveTokens parses ``content`` and resolves the tokens in the context of a guild.
+//
+// ``known`` users, such as the mentions of a message, are used before looking anywhere else.
+func (c *DiscordClient) ResolveTokens(guild, content string, known ...*DiscordUser) []*MessageToken {
+	var g *DiscordGuild
+	toks := format.Tokenize(content)
+	result := make([]*MessageToken, len(toks))
+	for i, tok := range toks {
+		mt := &MessageToken{Token: tok}
+		result[i] = mt
+
+		switch tok.Type {
+		case format.TokenUser:
+			for _, u := range known {
+				if u.ID() == tok.ID {
+					mt.User = u
+					break
+				}
+			}
+			if mt.User == nil {
+				mt.User = c.Cache.GetUser(tok.ID)
+			}
+		case format.TokenChannel:
+			mt.Channel = c.Cache.GetChannel(tok.ID)
+		case format.TokenRole, format.TokenEmoji:
+			if g == nil && guild != "" {
+				g = c.Cache.GetGuild(guild)
+			}
+			if tok.Type == format.TokenRole {
+				if g != nil {
+					mt.Role = g.Role(tok.ID)
+				}
+				continue
+			}
+
+			if g != nil {
+				for _, e := range g.Emojis() {
+					if e.ID == tok.ID {
+						mt.Emoji = e
+						break
+					}
+				}
+			}
+			if mt.Emoji == nil {
+				mt.Emoji = &discordgo.Emoji{
+					ID:       tok.ID,
+					Name:     tok.Name,
+					Animated: tok.Animated,
+				}
+			}
+		}
+	}
+	return result
+}
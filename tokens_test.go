@@ -0,0 +1,47 @@
+package dgofw
+
+import (
+	"testing"
+
+	"github.com/Krognol/dgofw/format"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestResolveTokens(t *testing.T) {
+	c, requests := newMessageClient()
+	g := testGuild("1")
+	g.Roles = []*discordgo.Role{{ID: "5", Name: "mods"}}
+	g.Emojis = []*discordgo.Emoji{{ID: "6", Name: "cached"}}
+	c.Cache.UpdateGuild(g)
+
+	known := c.Cache.UpdateUser(&discordgo.User{ID: "9", Username: "known"})
+	toks := c.ResolveTokens("1", "<@3> <@9> <#2> <@&5> <:cached:6> <a:other:7> <t:1600000000>", known)
+	if len(toks) != 7 {
+		t.Fatalf("got %d tokens, want 7", len(toks))
+	}
+
+	if u := toks[0].User; u == nil || u.Username() != "user 3" {
+		t.Error("cached user not resolved")
+	}
+	if toks[1].User != known {
+		t.Error("known user not used")
+	}
+	if ch := toks[2].Channel; ch == nil || ch.ID() != "2" {
+		t.Error("channel not resolved")
+	}
+	if r := toks[3].Role; r == nil || r.Name() != "mods" {
+		t.Error("role not resolved")
+	}
+	if e := toks[4].Emoji; e == nil || e.Name != "cached" {
+		t.Error("guild emoji not resolved")
+	}
+	if e := toks[5].Emoji; e == nil || e.Name != "other" || !e.Animated {
+		t.Error("unknown emoji not built from the token")
+	}
+	if tok := toks[6]; tok.Type != format.TokenTimestamp || tok.User != nil || tok.Channel != nil {
+		t.Error("timestamp resolved to an entity")
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d requests, want 0", n)
+	}
+}
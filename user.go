@@ -1,14 +1,15 @@
 package dgofw
 
 import (
-	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type DiscordUser struct {
+	sync.RWMutex
 	client *DiscordClient
 	u      *discordgo.User
 }
@@ -20,24 +21,52 @@ func NewDiscordUser(client *DiscordClient, u *discordgo.User) *DiscordUser {
 	}
 }
 
+func (u *DiscordUser) raw() *discordgo.User {
+	u.RLock()
+	defer u.RUnlock()
+	return u.u
+}
+
+func (u *DiscordUser) set(raw *discordgo.User) {
+	u.Lock()
+	u.u = raw
+	u.Unlock()
+}
+
+// update replaces the user with a full one, and reports whether anything changed.
+// Partial users are ignored.
+func (u *DiscordUser) update(raw *discordgo.User) bool {
+	if raw.Username == "" {
+		return false
+	}
+
+	u.Lock()
+	defer u.Unlock()
+	if u.u != nil && *u.u == *raw {
+		return false
+	}
+	u.u = raw
+	return true
+}
+
 func (u *DiscordUser) Avatar() string {
-	return u.u.AvatarURL("256")
+	return u.raw().AvatarURL("256")
 }
 
 func (u *DiscordUser) ID() string {
-	return u.u.ID
+	return u.raw().ID
 }
 
 func (u *DiscordUser) Mention() string {
-	return u.u.Mention()
+	return u.raw().Mention()
 }
 
 func (u *DiscordUser) Username() string {
-	return u.u.Username
+	return u.raw().Username
 }
 
 func (u *DiscordUser) Discriminator() string {
-	return u.u.Discriminator
+	return u.raw().Discriminator
 }
 
 func (u *DiscordUser) Timestamp() string {
@@ -48,25 +77,15 @@ func (u *DiscordUser) Timestamp() string {
 }
 
 func (u *DiscordUser) Bot() bool {
-	return u.u.Bot
+	return u.raw().Bot
 }
 
 func (u *DiscordUser) Verified() bool {
-	return u.u.Verified
+	return u.raw().Verified
 }
 
 func (u *DiscordUser) AsMember(guild string) *DiscordMember {
-	if mem := u.client.Cache.GetMember(guild, u.ID()); mem != nil {
-		return mem
-	}
-
-	m, err := u.client.ses.GuildMember(guild, u.ID())
-	if err != nil {
-		fmt.Println(err)
-		return nil
-	}
-
-	return NewDiscordMember(u.client, m)
+	return u.client.Cache.GetMember(guild, u.ID())
 }
 
 func (u *DiscordUser) CreateDMChannel() *DiscordChannel {
@@ -0,0 +1,330 @@
+package dgofw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrNoWebhookToken is returned when sending through a webhook the bot didn't create.
+var ErrNoWebhookToken = errors.New("webhook has no token")
+
+type DiscordWebhook struct {
+	sync.RWMutex
+	client *DiscordClient
+	w      *discordgo.Webhook
+}
+
+// WebhookMessage is a message sent through a webhook.
+type WebhookMessage struct {
+	Content         string                    `json:"content,omitempty"`
+	Username        string                    `json:"username,omitempty"`
+	AvatarURL       string                    `json:"avatar_url,omitempty"`
+	TTS             bool                      `json:"tts,omitempty"`
+	Embeds          []*discordgo.MessageEmbed `json:"embeds,omitempty"`
+	AllowedMentions *AllowedMentions          `json:"allowed_mentions,omitempty"`
+
+	// ThreadID sends the message to a thread in the webhook's channel.
+	ThreadID string `json:"-"`
+}
+
+// webhookEdit holds the fields of a webhook message that can be edited.
+type webhookEdit struct {
+	Content         string                    `json:"content"`
+	Embeds          []*discordgo.MessageEmbed `json:"embeds"`
+	AllowedMentions *AllowedMentions          `json:"allowed_mentions,omitempty"`
+}
+
+func NewDiscordWebhook(client *DiscordClient, w *discordgo.Webhook) *DiscordWebhook {
+	return &DiscordWebhook{
+		client: client,
+		w:      w,
+	}
+}
+
+func (w *DiscordWebhook) raw() *discordgo.Webhook {
+	w.RLock()
+	defer w.RUnlock()
+	return w.w
+}
+
+func (w *DiscordWebhook) ID() string {
+	return w.raw().ID
+}
+
+func (w *DiscordWebhook) Name() string {
+	return w.raw().Name
+}
+
+func (w *DiscordWebhook) ChannelID() string {
+	return w.raw().ChannelID
+}
+
+func (w *DiscordWebhook) GuildID() string {
+	return w.raw().GuildID
+}
+
+// Token is needed to send messages, it is only set for webhooks created by a bot.
+func (w *DiscordWebhook) Token() string {
+	return w.raw().Token
+}
+
+// Creator returns the user who created the webhook, or nil.
+func (w *DiscordWebhook) Creator() *DiscordUser {
+	raw := w.raw()
+	if raw.User == nil {
+		return nil
+	}
+	return w.client.Cache.UpdateUser(raw.User)
+}
+
+func (w *DiscordWebhook) Client() *DiscordClient {
+	return w.client
+}
+
+// endpoint returns the tokened webhook endpoint, with ``path`` and ``query`` appended.
+func (w *DiscordWebhook) endpoint(path string, query url.Values) (string, error) {
+	token := w.Token()
+	if token == "" {
+		return "", ErrNoWebhookToken
+	}
+
+	uri := discordgo.EndpointWebhookToken(w.ID(), token) + path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	return uri, nil
+}
+
+// Execute sends a message through the webhook and returns it.
+//
+// The message uses the client's ``AllowedMentions`` policy unless it sets its own.
+func (w *DiscordWebhook) Execute(msg *WebhookMessage) (*DiscordMessage, error) {
+	q := url.Values{}
+	q.Set("wait", "true")
+	if msg.ThreadID != "" {
+		q.Set("thread_id", msg.ThreadID)
+	}
+	uri, err := w.endpoint("", q)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.AllowedMentions == nil {
+		w.client.RLock()
+		policy := w.client.AllowedMentions
+		w.client.RUnlock()
+
+		cp := *msg
+		cp.AllowedMentions = policy.copy()
+		msg = &cp
+	}
+
+	res, err := w.client.ses.RequestWithBucketID("POST", uri, msg, discordgo.EndpointWebhookToken(w.ID(), ""))
+	if err != nil {
+		return nil, err
+	}
+	return w.decodeMessage(res)
+}
+
+// Send sends plain text through the webhook.
+func (w *DiscordWebhook) Send(content string) (*DiscordMessage, error) {
+	return w.Execute(&WebhookMessage{Content: content})
+}
+
+// EditMessage replaces the content and embeds of a message sent by the webhook.
+func (w *DiscordWebhook) EditMessage(id, content string, embeds ...*discordgo.MessageEmbed) (*DiscordMessage, error) {
+	data := &webhookEdit{
+		Content: content,
+		Embeds:  embeds,
+	}
+	if data.Embeds == nil {
+		data.Embeds = make([]*discordgo.MessageEmbed, 0)
+	}
+
+	uri, err := w.endpoint("/messages/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := w.client.ses.RequestWithBucketID("PATCH", uri, data, discordgo.EndpointWebhookToken(w.ID(), "")+"/messages")
+	if err != nil {
+		return nil, err
+	}
+	return w.decodeMessage(res)
+}
+
+// DeleteMessage deletes a message sent by the webhook.
+func (w *DiscordWebhook) DeleteMessage(id string) error {
+	uri, err := w.endpoint("/messages/"+id, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.client.ses.RequestWithBucketID("DELETE", uri, nil, discordgo.EndpointWebhookToken(w.ID(), "")+"/messages")
+	return err
+}
+
+func (w *DiscordWebhook) decodeMessage(data []byte) (*DiscordMessage, error) {
+	m := new(discordgo.Message)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return NewDiscordMessage(w.client, m), nil
+}
+
+// Edit changes the default name and avatar of the webhook. ``avatar`` is a data URI, and may be empty.
+func (w *DiscordWebhook) Edit(name, avatar string) error {
+	if _, err := w.client.ses.WebhookEdit(w.ID(), name, avatar, ""); err != nil {
+		return err
+	}
+
+	w.Lock()
+	raw := *w.w
+	raw.Name = name
+	w.w = &raw
+	w.Unlock()
+	return nil
+}
+
+func (w *DiscordWebhook) Delete() error {
+	return w.client.ses.WebhookDelete(w.ID())
+}
+
+// Webhooks returns the webhooks of the channel.
+func (c *DiscordChannel) Webhooks() ([]*DiscordWebhook, error) {
+	hooks, err := c.client.ses.ChannelWebhooks(c.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DiscordWebhook, len(hooks))
+	for i, w := range hooks {
+		result[i] = NewDiscordWebhook(c.client, w)
+	}
+	return result, nil
+}
+
+// CreateWebhook creates a webhook in the channel. ``avatar`` is a data URI, and may be empty.
+func (c *DiscordChannel) CreateWebhook(name, avatar string) (*DiscordWebhook, error) {
+	w, err := c.client.ses.WebhookCreate(c.ID(), name, avatar)
+	if err != nil {
+		return nil, err
+	}
+	return NewDiscordWebhook(c.client, w), nil
+}
+
+// Webhooks returns the webhooks of the guild.
+func (g *DiscordGuild) Webhooks() ([]*DiscordWebhook, error) {
+	hooks, err := g.client.ses.GuildWebhooks(g.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DiscordWebhook, len(hooks))
+	for i, w := range hooks {
+		result[i] = NewDiscordWebhook(g.client, w)
+	}
+	return result, nil
+}
+
+// WebhookManager sends messages through one webhook per channel, creating it when needed.
+// Webhooks the bot created earlier with the same name are reused.
+type WebhookManager struct {
+	sync.Mutex
+	client *DiscordClient
+	name   string
+	hooks  map[string]*DiscordWebhook
+}
+
+// NewWebhookManager creates a manager for webhooks called ``name``.
+func (c *DiscordClient) NewWebhookManager(name string) *WebhookManager {
+	return &WebhookManager{
+		client: c,
+		name:   name,
+		hooks:  make(map[string]*DiscordWebhook),
+	}
+}
+
+// Get returns the webhook of a channel.
+func (m *WebhookManager) Get(channel string) (*DiscordWebhook, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if w, ok := m.hooks[channel]; ok {
+		return w, nil
+	}
+
+	ch := m.client.Cache.GetChannel(channel)
+	if ch == nil {
+		return nil, fmt.Errorf("channel %s not found", channel)
+	}
+
+	hooks, err := ch.Webhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	me := m.client.ses.State.User.ID
+	for _, w := range hooks {
+		raw := w.raw()
+		if raw.Name == m.name && raw.Token != "" && raw.User != nil && raw.User.ID == me {
+			m.hooks[channel] = w
+			return w, nil
+		}
+	}
+
+	w, err := ch.CreateWebhook(m.name, "")
+	if err != nil {
+		return nil, err
+	}
+	m.hooks[channel] = w
+	return w, nil
+}
+
+// Forget drops the webhook of a channel, so the next send looks it up again.
+func (m *WebhookManager) Forget(channel string) {
+	m.Lock()
+	delete(m.hooks, channel)
+	m.Unlock()
+}
+
+// Send sends a message to a channel. Messages to threads go through the webhook of the parent channel.
+//
+// If the webhook was deleted in the meantime, a new one is created.
+func (m *WebhookManager) Send(channel string, msg *WebhookMessage) (*DiscordMessage, error) {
+	if ch := m.client.Cache.GetChannel(channel); ch != nil && ch.IsThread() {
+		cp := *msg
+		cp.ThreadID = channel
+		msg, channel = &cp, ch.ParentID()
+	}
+
+	w, err := m.Get(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := w.Execute(msg)
+	if rerr, ok := err.(*discordgo.RESTError); ok && rerr.Response != nil && rerr.Response.StatusCode == http.StatusNotFound {
+		m.Forget(channel)
+		if w, err = m.Get(channel); err != nil {
+			return nil, err
+		}
+		return w.Execute(msg)
+	}
+	return result, err
+}
+
+// SendAs sends a message to a channel with the name and avatar of ``u``.
+func (m *WebhookManager) SendAs(channel string, u *DiscordUser, content string) (*DiscordMessage, error) {
+	return m.Send(channel, &WebhookMessage{
+		Content:   content,
+		Username:  u.Username(),
+		AvatarURL: u.Avatar(),
+	})
+}
@@ -0,0 +1,22 @@
+package dgofw
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestWebhookWithoutToken(t *testing.T) {
+	c, requests := newTestClient()
+	w := NewDiscordWebhook(c, &discordgo.Webhook{ID: "1", ChannelID: "2"})
+
+	if _, err := w.Send("hello"); err != ErrNoWebhookToken {
+		t.Errorf("got %v, want %v", err, ErrNoWebhookToken)
+	}
+	if err := w.DeleteMessage("3"); err != ErrNoWebhookToken {
+		t.Errorf("got %v, want %v", err, ErrNoWebhookToken)
+	}
+	if n := requests.count(); n != 0 {
+		t.Errorf("made %d requests, want 0", n)
+	}
+}